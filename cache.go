@@ -0,0 +1,42 @@
+package secretsmanager
+
+import (
+	"time"
+
+	"github.com/selectel/secretsmanager-go/internal/cache"
+	"github.com/selectel/secretsmanager-go/service/certs"
+	"github.com/selectel/secretsmanager-go/service/secrets"
+)
+
+// CacheOptions — lifetimes of entries of the in-memory cache of Secrets.Get and Certificates.Get.
+type CacheOptions struct {
+	// TTL is how long a value is served without requests to the API.
+	TTL time.Duration
+
+	// NegativeTTL is how long a NOT_FOUND error is served without requests to the API,
+	// zero disables caching of errors. Other errors are never cached.
+	NegativeTTL time.Duration
+
+	// StaleWhileRevalidate is how long an expired value is still served, while it is refreshed in the background.
+	// Zero means, that an expired value is requested before it is returned.
+	StaleWhileRevalidate time.Duration
+}
+
+// WithCache is a functional parameter for SecretsManagerClient, used to cache secrets and certificates
+// returned by Get. Concurrent misses of the same key are served by a single request.
+// Writes made by the client invalidate cached entries, changes made by others are seen after TTL.
+func WithCache(opts CacheOptions) ClientOption {
+	return func(c *Client) {
+		c.cfg.cacheOpts = &opts
+	}
+}
+
+// serviceOptions — returns options of Secrets and Certificates services built from the config.
+func (cfg *config) serviceOptions() ([]secrets.Option, []certs.Option) {
+	if cfg.cacheOpts == nil {
+		return nil, nil
+	}
+
+	opts := cache.Options(*cfg.cacheOpts)
+	return []secrets.Option{secrets.WithCache(opts)}, []certs.Option{certs.WithCache(opts)}
+}
@@ -0,0 +1,358 @@
+package secretsmanager
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+// Environment variables, that are used by NewFromEnvironment.
+const (
+	envCloud            = "OS_CLOUD"
+	envClientConfigFile = "OS_CLIENT_CONFIG_FILE"
+
+	envAuthURL                     = "OS_AUTH_URL"
+	envToken                       = "OS_TOKEN"
+	envUsername                    = "OS_USERNAME"
+	envPassword                    = "OS_PASSWORD"
+	envDomainName                  = "OS_DOMAIN_NAME"
+	envUserDomainName              = "OS_USER_DOMAIN_NAME"
+	envProjectID                   = "OS_PROJECT_ID"
+	envProjectName                 = "OS_PROJECT_NAME"
+	envProjectDomainName           = "OS_PROJECT_DOMAIN_NAME"
+	envApplicationCredentialID     = "OS_APPLICATION_CREDENTIAL_ID"
+	envApplicationCredentialSecret = "OS_APPLICATION_CREDENTIAL_SECRET"
+	envRegionName                  = "OS_REGION_NAME"
+	envInterface                   = "OS_INTERFACE"
+
+	envSecretsManagerEndpoint     = "OS_SECRETS_MANAGER_ENDPOINT_OVERRIDE"
+	envCertificateManagerEndpoint = "OS_CERTIFICATE_MANAGER_ENDPOINT_OVERRIDE"
+)
+
+// cloudsFileName is a name of OpenStack client configuration file.
+const cloudsFileName = "clouds.yaml"
+
+// environmentKeys maps environment variables to keys of a clouds.yaml profile.
+func environmentKeys() map[string]string {
+	return map[string]string{
+		envAuthURL:                     "auth.auth_url",
+		envToken:                       "auth.token",
+		envUsername:                    "auth.username",
+		envPassword:                    "auth.password",
+		envDomainName:                  "auth.domain_name",
+		envUserDomainName:              "auth.user_domain_name",
+		envProjectID:                   "auth.project_id",
+		envProjectName:                 "auth.project_name",
+		envProjectDomainName:           "auth.project_domain_name",
+		envApplicationCredentialID:     "auth.application_credential_id",
+		envApplicationCredentialSecret: "auth.application_credential_secret",
+		envRegionName:                  "region_name",
+		envInterface:                   "interface",
+		envSecretsManagerEndpoint:      "secrets_manager_endpoint_override",
+		envCertificateManagerEndpoint:  "certificate_manager_endpoint_override",
+	}
+}
+
+// setting is a single configuration value with a description of where it was taken from,
+// so errors can point at the exact environment variable or clouds.yaml key.
+type setting struct {
+	value  string
+	source string
+}
+
+type settings map[string]setting
+
+func (s settings) get(env string) string {
+	return s[env].value
+}
+
+func (s settings) has(env string) bool {
+	return len(s[env].value) > 0
+}
+
+// describe returns a source of the setting, or all places it could be taken from if it is not set.
+func (s settings) describe(env string) string {
+	if st, ok := s[env]; ok {
+		return st.source
+	}
+
+	return fmt.Sprintf("%s (or %s in %s)", env, environmentKeys()[env], cloudsFileName)
+}
+
+// first returns a value of the first setting that is set.
+func (s settings) first(envs ...string) (string, string) {
+	for _, env := range envs {
+		if s.has(env) {
+			return s.get(env), env
+		}
+	}
+
+	return "", envs[0]
+}
+
+// NewFromEnvironment creates a client configured from OS_* environment variables.
+// If OS_CLOUD is set, the named profile from clouds.yaml is loaded first
+// (OS_CLIENT_CONFIG_FILE, ./clouds.yaml, ~/.config/openstack/clouds.yaml
+// and /etc/openstack/clouds.yaml are looked up in that order),
+// environment variables override values from the profile.
+// Options are applied on top of the loaded configuration: credentials from the environment are checked
+// and used only if neither WithAuthOpts nor WithTokenProvider is passed.
+func NewFromEnvironment(options ...ClientOption) (*Client, error) {
+	cfg, err := configFromEnvironment(os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	return newClient(cfg, options...)
+}
+
+// configFromEnvironment — builds a config from environment and clouds.yaml,
+// lookup is a function used to read environment variables.
+func configFromEnvironment(lookup func(string) (string, bool)) (*config, error) {
+	st, err := loadSettings(lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := defaultConfig()
+	cfg.envAuthOpts = func() (*AuthOpts, error) {
+		return authOptsFromSettings(st)
+	}
+	cfg.Region = st.get(envRegionName)
+	if st.has(envInterface) {
+		cfg.EndpointInterface = st.get(envInterface)
+	}
+
+	if st.has(envSecretsManagerEndpoint) {
+		cfg.APIURLSecrets = st.get(envSecretsManagerEndpoint)
+	}
+	if st.has(envCertificateManagerEndpoint) {
+		cfg.APIURLUserCertificates = st.get(envCertificateManagerEndpoint)
+	}
+
+	return cfg, nil
+}
+
+// loadSettings — reads a clouds.yaml profile, if OS_CLOUD is set, and overrides it with environment variables.
+func loadSettings(lookup func(string) (string, bool)) (settings, error) {
+	st := settings{}
+
+	if cloud, ok := lookup(envCloud); ok && len(cloud) > 0 {
+		path, err := findCloudsFile(lookup)
+		if err != nil {
+			return nil, err
+		}
+
+		profile, err := loadCloudProfile(path, cloud)
+		if err != nil {
+			return nil, err
+		}
+
+		for env, key := range environmentKeys() {
+			if value := profileValue(profile, key); len(value) > 0 {
+				st[env] = setting{
+					value:  value,
+					source: fmt.Sprintf("clouds.%s.%s in %s", cloud, key, path),
+				}
+			}
+		}
+	}
+
+	for env := range environmentKeys() {
+		if value, ok := lookup(env); ok && len(value) > 0 {
+			st[env] = setting{value: value, source: env}
+		}
+	}
+
+	return st, nil
+}
+
+// findCloudsFile — returns a path to the first existing clouds.yaml.
+func findCloudsFile(lookup func(string) (string, bool)) (string, error) {
+	if path, ok := lookup(envClientConfigFile); ok && len(path) > 0 {
+		if _, err := os.Stat(path); err != nil {
+			return "", secretsmanagererrors.Error{
+				Err:  secretsmanagererrors.ErrClientBadConfig,
+				Desc: fmt.Sprintf("%s points to a file that can't be read: %s", envClientConfigFile, err),
+			}
+		}
+		return path, nil
+	}
+
+	candidates := []string{cloudsFileName}
+	if configDir, err := os.UserConfigDir(); err == nil {
+		candidates = append(candidates, filepath.Join(configDir, "openstack", cloudsFileName))
+	}
+	candidates = append(candidates, filepath.Join("/etc", "openstack", cloudsFileName))
+
+	for _, path := range candidates {
+		_, err := os.Stat(path)
+		if err == nil {
+			return path, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", secretsmanagererrors.Error{
+				Err:  secretsmanagererrors.ErrClientBadConfig,
+				Desc: fmt.Sprintf("can't read %s: %s", path, err),
+			}
+		}
+	}
+
+	return "", secretsmanagererrors.Error{
+		Err: secretsmanagererrors.ErrClientBadConfig,
+		Desc: fmt.Sprintf("%s is set, but %s is not found in any of: %s",
+			envCloud, cloudsFileName, strings.Join(candidates, ", ")),
+	}
+}
+
+// loadCloudProfile — reads a profile with the given name from clouds.yaml.
+func loadCloudProfile(path, cloud string) (map[string]any, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrClientBadConfig,
+			Desc: fmt.Sprintf("can't read %s: %s", path, err),
+		}
+	}
+
+	var file struct {
+		Clouds map[string]map[string]any `yaml:"clouds"`
+	}
+	err = yaml.Unmarshal(raw, &file)
+	if err != nil {
+		return nil, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrClientBadConfig,
+			Desc: fmt.Sprintf("can't parse %s: %s", path, err),
+		}
+	}
+
+	profile, ok := file.Clouds[cloud]
+	if !ok {
+		return nil, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrClientBadConfig,
+			Desc: fmt.Sprintf("%s is set to %q, but there is no such cloud in %s", envCloud, cloud, path),
+		}
+	}
+
+	return profile, nil
+}
+
+// profileValue — returns a scalar value by a dotted key like "auth.username".
+func profileValue(profile map[string]any, key string) string {
+	var node any = profile
+	for _, part := range strings.Split(key, ".") {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return ""
+		}
+		node = m[part]
+	}
+
+	switch v := node.(type) {
+	case nil, map[string]any, []any:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// authOptsFromSettings — builds AuthOpts and reports exactly which setting is missing or conflicting.
+func authOptsFromSettings(st settings) (*AuthOpts, error) {
+	var methods []string
+	if st.has(envToken) {
+		methods = append(methods, st.describe(envToken))
+	}
+	if st.has(envUsername) || st.has(envPassword) {
+		methods = append(methods, st.describe(envUsername))
+	}
+	if st.has(envApplicationCredentialID) || st.has(envApplicationCredentialSecret) {
+		methods = append(methods, st.describe(envApplicationCredentialID))
+	}
+
+	switch {
+	case len(methods) == 0:
+		return nil, secretsmanagererrors.Error{
+			Err: secretsmanagererrors.ErrClientNoAuthOpts,
+			Desc: fmt.Sprintf("no credentials found, set either %s, or %s and %s, or %s and %s",
+				envToken, envUsername, envPassword, envApplicationCredentialID, envApplicationCredentialSecret),
+		}
+	case len(methods) > 1:
+		return nil, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrClientNoAuthOpts,
+			Desc: "conflicting credentials, only one of them can be set: " + strings.Join(methods, ", "),
+		}
+	}
+
+	authOpts := &AuthOpts{
+		AuthURL: st.get(envAuthURL),
+	}
+
+	if st.has(envToken) {
+		authOpts.KeystoneToken = st.get(envToken)
+		return authOpts, nil
+	}
+
+	var missing []string
+	if st.has(envApplicationCredentialID) || st.has(envApplicationCredentialSecret) {
+		for _, env := range []string{envApplicationCredentialID, envApplicationCredentialSecret} {
+			if !st.has(env) {
+				missing = append(missing, st.describe(env))
+			}
+		}
+		authOpts.ApplicationCredentialID = st.get(envApplicationCredentialID)
+		authOpts.ApplicationCredentialSecret = st.get(envApplicationCredentialSecret)
+	} else {
+		missing = passwordSettings(st, authOpts)
+	}
+
+	if len(missing) > 0 {
+		return nil, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrClientNoAuthOpts,
+			Desc: "missing settings: " + strings.Join(missing, ", "),
+		}
+	}
+
+	return authOpts, nil
+}
+
+// passwordSettings — fills AuthOpts for the password method and returns descriptions of missing settings.
+func passwordSettings(st settings, authOpts *AuthOpts) []string {
+	var missing []string
+	for _, env := range []string{envUsername, envPassword} {
+		if !st.has(env) {
+			missing = append(missing, st.describe(env))
+		}
+	}
+
+	domainName, domainEnv := st.first(envUserDomainName, envDomainName)
+	if len(domainName) == 0 {
+		missing = append(missing, st.describe(domainEnv))
+	}
+
+	projectDomainName, projectDomainEnv := st.first(envProjectDomainName, envDomainName)
+	if !st.has(envProjectID) {
+		if !st.has(envProjectName) {
+			missing = append(missing, st.describe(envProjectID)+" or "+st.describe(envProjectName))
+		} else if len(projectDomainName) == 0 {
+			missing = append(missing, st.describe(projectDomainEnv))
+		}
+	}
+
+	authOpts.Username = st.get(envUsername)
+	authOpts.Password = st.get(envPassword)
+	authOpts.DomainName = domainName
+	authOpts.ProjectID = st.get(envProjectID)
+	authOpts.ProjectName = st.get(envProjectName)
+	authOpts.ProjectDomainName = projectDomainName
+
+	return missing
+}
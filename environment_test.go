@@ -0,0 +1,160 @@
+package secretsmanager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+const testCloudsYAML = `
+clouds:
+  prod:
+    auth:
+      auth_url: https://keystone.example.com/v3
+      username: user
+      password: from-file
+      user_domain_name: "123456"
+      project_name: payments
+      project_domain_name: "123456"
+    region_name: ru-1
+    secrets_manager_endpoint_override: https://secrets.example.com/
+`
+
+func mapLookup(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+func writeCloudsFile(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "clouds.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testCloudsYAML), 0o600))
+
+	return path
+}
+
+func TestConfigFromEnvironmentVariables(t *testing.T) {
+	cfg, err := configFromEnvironment(mapLookup(map[string]string{
+		envApplicationCredentialID:     "app-id",
+		envApplicationCredentialSecret: "app-secret",
+		envRegionName:                  "ru-3",
+		envCertificateManagerEndpoint:  "https://certs.example.com/",
+	}))
+	require.NoError(t, err)
+	require.NoError(t, cfg.resolveEnvAuthOpts())
+
+	require.Equal(t, &AuthOpts{
+		ApplicationCredentialID:     "app-id",
+		ApplicationCredentialSecret: "app-secret",
+	}, cfg.authOpts)
+	require.Equal(t, "ru-3", cfg.Region)
+	require.Equal(t, defaultAPIURLSecrets, cfg.APIURLSecrets)
+	require.Equal(t, "https://certs.example.com/", cfg.APIURLUserCertificates)
+}
+
+func TestConfigFromCloudsYAML(t *testing.T) {
+	cfg, err := configFromEnvironment(mapLookup(map[string]string{
+		envCloud:            "prod",
+		envClientConfigFile: writeCloudsFile(t),
+		envPassword:         "from-env",
+	}))
+	require.NoError(t, err)
+	require.NoError(t, cfg.resolveEnvAuthOpts())
+
+	require.Equal(t, &AuthOpts{
+		AuthURL:           "https://keystone.example.com/v3",
+		Username:          "user",
+		Password:          "from-env",
+		DomainName:        "123456",
+		ProjectName:       "payments",
+		ProjectDomainName: "123456",
+	}, cfg.authOpts)
+	require.Equal(t, "ru-1", cfg.Region)
+	require.Equal(t, "https://secrets.example.com/", cfg.APIURLSecrets)
+}
+
+func TestConfigFromEnvironmentErrors(t *testing.T) {
+	cloudsFile := writeCloudsFile(t)
+
+	tests := map[string]struct {
+		env     map[string]string
+		expErr  error
+		expDesc string
+	}{
+		"No Credentials": {
+			map[string]string{},
+			secretsmanagererrors.ErrClientNoAuthOpts,
+			"no credentials found",
+		},
+		"Conflicting Credentials": {
+			map[string]string{envToken: "dummy", envApplicationCredentialID: "app-id"},
+			secretsmanagererrors.ErrClientNoAuthOpts,
+			"conflicting credentials, only one of them can be set: OS_TOKEN, OS_APPLICATION_CREDENTIAL_ID",
+		},
+		"Missing Password And Project": {
+			map[string]string{envUsername: "user", envUserDomainName: "123456"},
+			secretsmanagererrors.ErrClientNoAuthOpts,
+			"missing settings: OS_PASSWORD (or auth.password in clouds.yaml), " +
+				"OS_PROJECT_ID (or auth.project_id in clouds.yaml) or " +
+				"OS_PROJECT_NAME (or auth.project_name in clouds.yaml)",
+		},
+		"Missing Application Credential Secret": {
+			map[string]string{envApplicationCredentialID: "app-id"},
+			secretsmanagererrors.ErrClientNoAuthOpts,
+			"missing settings: OS_APPLICATION_CREDENTIAL_SECRET",
+		},
+		"Unknown Cloud": {
+			map[string]string{envCloud: "staging", envClientConfigFile: cloudsFile},
+			secretsmanagererrors.ErrClientBadConfig,
+			`OS_CLOUD is set to "staging", but there is no such cloud`,
+		},
+		"Missing Clouds File": {
+			map[string]string{envCloud: "prod", envClientConfigFile: filepath.Join(t.TempDir(), "missing.yaml")},
+			secretsmanagererrors.ErrClientBadConfig,
+			"OS_CLIENT_CONFIG_FILE points to a file that can't be read",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := configFromEnvironment(mapLookup(test.env))
+			if err == nil {
+				err = cfg.resolveEnvAuthOpts()
+			}
+			require.ErrorIs(t, err, test.expErr)
+			require.ErrorContains(t, err, test.expDesc)
+		})
+	}
+}
+
+func TestNewFromEnvironmentWithTokenProvider(t *testing.T) {
+	provider, err := NewKeystoneTokenProvider("provided-token")
+	require.NoError(t, err)
+
+	for name, env := range map[string]map[string]string{
+		"No Credentials":   {},
+		"With Credentials": {envToken: "env-token"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(envCloud, "")
+			for key := range environmentKeys() {
+				t.Setenv(key, "")
+			}
+			for key, value := range env {
+				t.Setenv(key, value)
+			}
+
+			cl, err := NewFromEnvironment(WithTokenProvider(provider))
+			require.NoError(t, err)
+			require.Nil(t, cl.cfg.authOpts)
+			require.Equal(t, provider, cl.cfg.tokenProvider)
+		})
+	}
+}
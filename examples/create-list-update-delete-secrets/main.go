@@ -28,7 +28,7 @@ func main() {
 	mySecret := secrets.UserSecret{
 		Key:         "John-Cena",
 		Description: "nothing happened in tiananmen square 1989",
-		Value:       "Zǎo shang hǎo zhōng guó!",
+		Value:       []byte("Zǎo shang hǎo zhōng guó!"),
 	}
 
 	// Uploading it into Secret Manager.
@@ -1,14 +1,39 @@
 package auth
 
-import "github.com/selectel/secretsmanager-go/secretsmanagererrors"
+import (
+	"context"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
 
 // Type — provides a general behaviour for all availbale Auth types;
-// it is a bridge from AuthOpts, provided by user
-// for retrieving KeystoneToken from GetKeystoneToken().
-// for example if we consider to add BasicAuth (login, pass)
-// it should have a realization of GetKeystoneToken().
+// it is a bridge from AuthOpts or a TokenProvider, provided by user,
+// for retrieving KeystoneToken from Token().
+// Implementations that issue tokens themselves (like password auth)
+// may perform a request to Keystone, so a context is passed and an error can be returned.
+// It has the same method set as the public secretsmanager.TokenProvider.
 type Type interface {
-	GetKeystoneToken() string
+	Token(ctx context.Context) (string, error)
+}
+
+// Renewable — is implemented by auth types, that are able to issue a new token
+// after the current one has been rejected by the backend.
+type Renewable interface {
+	Type
+
+	// InvalidateToken drops a cached token, but only if it is still the rejected one,
+	// so concurrent callers that got the same rejection cause a single re-issue.
+	InvalidateToken(rejected string)
+}
+
+// CatalogProvider — is implemented by auth types, that receive
+// a Keystone service catalog along with an issued token.
+type CatalogProvider interface {
+	Type
+
+	// EndpointURL returns URL of a service with the given type for the region and interface
+	// (public, internal or admin) from the catalog of a current token.
+	EndpointURL(ctx context.Context, serviceType, region, iface string) (string, error)
 }
 
 func NewKeystoneTokenAuth(kst string) (Type, error) {
@@ -28,6 +53,6 @@ type keystoneTokenAuth struct {
 	kst string
 }
 
-func (ksa *keystoneTokenAuth) GetKeystoneToken() string {
-	return ksa.kst
+func (ksa *keystoneTokenAuth) Token(_ context.Context) (string, error) {
+	return ksa.kst, nil
 }
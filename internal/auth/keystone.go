@@ -0,0 +1,319 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+const (
+	// defaultAuthHTTPTimeout represents the default timeout (in seconds) for requests to Keystone.
+	defaultAuthHTTPTimeout = 60
+
+	// tokenRefreshMargin represents how long before expiration the token is re-issued.
+	tokenRefreshMargin = 5 * time.Minute
+
+	// subjectTokenHeader is a header in which Keystone returns an issued token.
+	subjectTokenHeader = "X-Subject-Token"
+)
+
+// PasswordCredentials — credentials of a user that are exchanged for a Keystone token.
+type PasswordCredentials struct {
+	Username   string
+	Password   string
+	DomainName string
+}
+
+// Scope — a project the issued Keystone token is scoped to.
+// Either ProjectID or ProjectName with ProjectDomainName has to be set.
+type Scope struct {
+	ProjectID         string
+	ProjectName       string
+	ProjectDomainName string
+}
+
+// NewKeystonePasswordAuth returns an auth Type, that issues Keystone tokens
+// via POST {authURL}/auth/tokens using the password method and refreshes them before they expire.
+// If httpClient is nil, a client with default timeout is used.
+func NewKeystonePasswordAuth(
+	authURL string, creds PasswordCredentials, scope Scope, httpClient *http.Client,
+) (Type, error) {
+	if len(creds.Username) == 0 || len(creds.Password) == 0 {
+		return nil, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrClientNoAuthOpts,
+			Desc: "both Username and Password have to be provided",
+		}
+	}
+
+	if len(creds.DomainName) == 0 {
+		return nil, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrClientNoAuthOpts,
+			Desc: "provided DomainName is empty",
+		}
+	}
+
+	ps, err := newProjectScope(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	req := authRequest{
+		Auth: authRequestAuth{
+			Identity: authIdentity{
+				Methods: []string{"password"},
+				Password: &passwordIdentity{
+					User: authUser{
+						Name:     creds.Username,
+						Password: creds.Password,
+						Domain:   &authDomain{Name: creds.DomainName},
+					},
+				},
+			},
+			Scope: ps,
+		},
+	}
+
+	return newKeystoneTokenIssuer(authURL, req, httpClient)
+}
+
+// NewKeystoneApplicationCredentialAuth returns an auth Type, that issues Keystone tokens
+// via POST {authURL}/auth/tokens using the application_credential method and refreshes them before they expire.
+// Application credentials are already bound to a project, so no scope is sent.
+// If httpClient is nil, a client with default timeout is used.
+func NewKeystoneApplicationCredentialAuth(authURL, id, secret string, httpClient *http.Client) (Type, error) {
+	if len(id) == 0 || len(secret) == 0 {
+		return nil, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrClientNoAuthOpts,
+			Desc: "both ApplicationCredentialID and ApplicationCredentialSecret have to be provided",
+		}
+	}
+
+	req := authRequest{
+		Auth: authRequestAuth{
+			Identity: authIdentity{
+				Methods: []string{"application_credential"},
+				ApplicationCredential: &applicationCredentialIdentity{
+					ID:     id,
+					Secret: secret,
+				},
+			},
+		},
+	}
+
+	return newKeystoneTokenIssuer(authURL, req, httpClient)
+}
+
+func newProjectScope(scope Scope) (*authScope, error) {
+	switch {
+	case len(scope.ProjectID) > 0:
+		return &authScope{Project: &authProject{ID: scope.ProjectID}}, nil
+	case len(scope.ProjectName) > 0 && len(scope.ProjectDomainName) > 0:
+		return &authScope{Project: &authProject{
+			Name:   scope.ProjectName,
+			Domain: &authDomain{Name: scope.ProjectDomainName},
+		}}, nil
+	default:
+		return nil, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrClientNoAuthOpts,
+			Desc: "either ProjectID or ProjectName with ProjectDomainName have to be provided",
+		}
+	}
+}
+
+// keystoneTokenIssuer represents an authentication method, that issues Keystone tokens by itself.
+// It conforms to Renewable and CatalogProvider interfaces and is safe for concurrent use.
+type keystoneTokenIssuer struct {
+	endpoint   string
+	reqBody    []byte
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	catalog   []catalogService
+	refreshAt time.Time
+	expiresAt time.Time
+}
+
+func newKeystoneTokenIssuer(authURL string, req authRequest, httpClient *http.Client) (*keystoneTokenIssuer, error) {
+	if len(authURL) == 0 {
+		return nil, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrClientNoAuthOpts,
+			Desc: "provided AuthURL is empty",
+		}
+	}
+
+	endpoint, err := url.JoinPath(authURL, "auth", "tokens")
+	if err != nil {
+		return nil, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrCannotFormatEndpoint,
+			Desc: err.Error(),
+		}
+	}
+
+	marshalled, err := json.Marshal(req)
+	if err != nil {
+		return nil, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrInternalAppError,
+			Desc: err.Error(),
+		}
+	}
+
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultAuthHTTPTimeout * time.Second}
+	}
+
+	return &keystoneTokenIssuer{
+		endpoint:   endpoint,
+		reqBody:    marshalled,
+		httpClient: httpClient,
+		now:        time.Now,
+	}, nil
+}
+
+// Token returns a cached token or issues a new one,
+// if there is no token yet or it is about to expire.
+func (ki *keystoneTokenIssuer) Token(ctx context.Context) (string, error) {
+	ki.mu.Lock()
+	defer ki.mu.Unlock()
+
+	return ki.tokenLocked(ctx)
+}
+
+// tokenLocked — the same as Token, but expects ki.mu to be held by the caller.
+// If a token fails to be refreshed before expiration, the cached one is returned, until it expires.
+func (ki *keystoneTokenIssuer) tokenLocked(ctx context.Context) (string, error) {
+	if len(ki.token) > 0 && ki.now().Before(ki.refreshAt) {
+		return ki.token, nil
+	}
+
+	token, issued, err := ki.issue(ctx)
+	if err != nil {
+		if len(ki.token) > 0 && ki.now().Before(ki.expiresAt) {
+			return ki.token, nil
+		}
+		return "", err
+	}
+	expiresAt := issued.ExpiresAt
+
+	// Refresh token either tokenRefreshMargin before expiration
+	// or at the half of its lifetime, if the token is short-lived.
+	issuedAt := ki.now()
+	margin := tokenRefreshMargin
+	if lifetime := expiresAt.Sub(issuedAt); lifetime < 2*margin {
+		margin = lifetime / 2 //nolint:gomnd
+	}
+
+	ki.token = token
+	ki.catalog = issued.Catalog
+	ki.refreshAt = expiresAt.Add(-margin)
+	ki.expiresAt = expiresAt
+
+	return ki.token, nil
+}
+
+// EndpointURL returns URL of a service from the catalog of a current token,
+// a token is issued, if there is no one yet.
+func (ki *keystoneTokenIssuer) EndpointURL(ctx context.Context, serviceType, region, iface string) (string, error) {
+	ki.mu.Lock()
+	defer ki.mu.Unlock()
+
+	_, err := ki.tokenLocked(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	for _, service := range ki.catalog {
+		if service.Type != serviceType {
+			continue
+		}
+
+		for _, endpoint := range service.Endpoints {
+			if endpoint.Interface != iface {
+				continue
+			}
+			if endpoint.RegionID == region || endpoint.Region == region {
+				return endpoint.URL, nil
+			}
+		}
+	}
+
+	return "", secretsmanagererrors.Error{
+		Err:  secretsmanagererrors.ErrClientBadConfig,
+		Desc: fmt.Sprintf("service catalog has no %s endpoint of %q in region %q", iface, serviceType, region),
+	}
+}
+
+// InvalidateToken forces the next Token call to issue a new token,
+// unless the rejected token has already been replaced.
+func (ki *keystoneTokenIssuer) InvalidateToken(rejected string) {
+	ki.mu.Lock()
+	defer ki.mu.Unlock()
+
+	if ki.token == rejected {
+		ki.token = ""
+	}
+}
+
+// issue performs a request to Keystone and returns a new token with its expiration time and catalog.
+func (ki *keystoneTokenIssuer) issue(ctx context.Context) (string, authToken, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ki.endpoint, bytes.NewReader(ki.reqBody))
+	if err != nil {
+		return "", authToken{}, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrInternalAppError,
+			Desc: err.Error(),
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := ki.httpClient.Do(req)
+	if err != nil {
+		return "", authToken{}, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrCannotIssueAuthToken,
+			Desc: err.Error(),
+		}
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", authToken{}, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrCannotReadBody,
+			Desc: err.Error(),
+		}
+	}
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return "", authToken{}, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrCannotIssueAuthToken,
+			Desc: fmt.Sprintf("keystone responded with status %d", resp.StatusCode),
+		}
+	}
+
+	token := resp.Header.Get(subjectTokenHeader)
+	if len(token) == 0 {
+		return "", authToken{}, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrCannotIssueAuthToken,
+			Desc: "keystone response has no " + subjectTokenHeader + " header",
+		}
+	}
+
+	var ar authResponse
+	err = json.Unmarshal(respBody, &ar)
+	if err != nil {
+		return "", authToken{}, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrCannotUnmarshalBody,
+			Desc: err.Error(),
+		}
+	}
+
+	return token, ar.Token, nil
+}
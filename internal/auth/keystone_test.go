@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+const testDummyAuthURL = "http://keystone.example.com/v3"
+
+type KeystoneSuite struct {
+	suite.Suite
+	httpClient *http.Client
+}
+
+func (suite *KeystoneSuite) SetupTest() {
+	suite.httpClient = &http.Client{Timeout: 10 * time.Second}
+	gock.InterceptClient(suite.httpClient)
+}
+
+func (suite *KeystoneSuite) TearDownTest() {
+	suite.Require().True(gock.IsDone())
+	gock.Off()
+}
+
+func TestSuiteKeystone(t *testing.T) {
+	suite.Run(t, new(KeystoneSuite))
+}
+
+func (suite *KeystoneSuite) newPasswordAuth() *keystoneTokenIssuer {
+	at, err := NewKeystonePasswordAuth(
+		testDummyAuthURL,
+		PasswordCredentials{Username: "user", Password: "pass", DomainName: "123456"},
+		Scope{ProjectID: "dummy-project"},
+		suite.httpClient,
+	)
+	suite.Require().NoError(err)
+
+	ki, ok := at.(*keystoneTokenIssuer)
+	suite.Require().True(ok)
+
+	return ki
+}
+
+func mockIssueToken(token string, expiresAt time.Time) {
+	gock.New(testDummyAuthURL).
+		Post("/auth/tokens").
+		MatchType("json").
+		JSON(map[string]any{
+			"auth": map[string]any{
+				"identity": map[string]any{
+					"methods": []string{"password"},
+					"password": map[string]any{
+						"user": map[string]any{
+							"name":     "user",
+							"password": "pass",
+							"domain":   map[string]any{"name": "123456"},
+						},
+					},
+				},
+				"scope": map[string]any{
+					"project": map[string]any{"id": "dummy-project"},
+				},
+			},
+		}).
+		Reply(http.StatusCreated).
+		SetHeader(subjectTokenHeader, token).
+		JSON(map[string]any{
+			"token": map[string]any{"expires_at": expiresAt.Format(time.RFC3339)},
+		})
+}
+
+func (suite *KeystoneSuite) TestIssueAndCache() {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ki := suite.newPasswordAuth()
+	ki.now = func() time.Time { return now }
+
+	mockIssueToken("first-token", now.Add(24*time.Hour))
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		token, err := ki.Token(ctx)
+		suite.Require().NoError(err)
+		suite.Equal("first-token", token)
+	}
+}
+
+func (suite *KeystoneSuite) TestRefreshBeforeExpiration() {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ki := suite.newPasswordAuth()
+	ki.now = func() time.Time { return now }
+
+	mockIssueToken("first-token", now.Add(24*time.Hour))
+	mockIssueToken("second-token", now.Add(48*time.Hour))
+
+	ctx := context.Background()
+	token, err := ki.Token(ctx)
+	suite.Require().NoError(err)
+	suite.Equal("first-token", token)
+
+	now = now.Add(24*time.Hour - tokenRefreshMargin)
+
+	token, err = ki.Token(ctx)
+	suite.Require().NoError(err)
+	suite.Equal("second-token", token)
+}
+
+func (suite *KeystoneSuite) TestRefreshFailedKeepsToken() {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ki := suite.newPasswordAuth()
+	ki.now = func() time.Time { return now }
+
+	mockIssueToken("first-token", now.Add(24*time.Hour))
+	gock.New(testDummyAuthURL).
+		Post("/auth/tokens").
+		Times(2).
+		Reply(http.StatusServiceUnavailable)
+
+	ctx := context.Background()
+	_, err := ki.Token(ctx)
+	suite.Require().NoError(err)
+
+	// The token is still valid, while it fails to be refreshed.
+	now = now.Add(24*time.Hour - time.Minute)
+	token, err := ki.Token(ctx)
+	suite.Require().NoError(err)
+	suite.Equal("first-token", token)
+
+	now = now.Add(time.Minute)
+	_, err = ki.Token(ctx)
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrCannotIssueAuthToken)
+}
+
+func (suite *KeystoneSuite) TestIssueFailed() {
+	ki := suite.newPasswordAuth()
+
+	gock.New(testDummyAuthURL).
+		Post("/auth/tokens").
+		Reply(http.StatusUnauthorized)
+
+	_, err := ki.Token(context.Background())
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrCannotIssueAuthToken)
+}
+
+func (suite *KeystoneSuite) TestNewKeystonePasswordAuthValidation() {
+	tests := map[string]struct {
+		creds PasswordCredentials
+		scope Scope
+	}{
+		"Empty Password": {
+			PasswordCredentials{Username: "user", DomainName: "123456"},
+			Scope{ProjectID: "dummy-project"},
+		},
+		"Empty Domain": {
+			PasswordCredentials{Username: "user", Password: "pass"},
+			Scope{ProjectID: "dummy-project"},
+		},
+		"Project Name Without Domain": {
+			PasswordCredentials{Username: "user", Password: "pass", DomainName: "123456"},
+			Scope{ProjectName: "dummy-project"},
+		},
+	}
+
+	for name, test := range tests {
+		suite.T().Run(name, func(t *testing.T) {
+			_, err := NewKeystonePasswordAuth(testDummyAuthURL, test.creds, test.scope, nil)
+			suite.Require().ErrorIs(err, secretsmanagererrors.ErrClientNoAuthOpts)
+		})
+	}
+}
+
+func (suite *KeystoneSuite) TestInvalidateTokenOnce() {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ki := suite.newPasswordAuth()
+	ki.now = func() time.Time { return now }
+
+	mockIssueToken("first-token", now.Add(24*time.Hour))
+	mockIssueToken("second-token", now.Add(24*time.Hour))
+
+	ctx := context.Background()
+	rejected, err := ki.Token(ctx)
+	suite.Require().NoError(err)
+
+	// Both callers got the same rejection, but the token has to be re-issued only once.
+	ki.InvalidateToken(rejected)
+	token, err := ki.Token(ctx)
+	suite.Require().NoError(err)
+	suite.Equal("second-token", token)
+
+	ki.InvalidateToken(rejected)
+	token, err = ki.Token(ctx)
+	suite.Require().NoError(err)
+	suite.Equal("second-token", token)
+}
+
+func (suite *KeystoneSuite) TestApplicationCredential() {
+	at, err := NewKeystoneApplicationCredentialAuth(testDummyAuthURL, "app-id", "app-secret", suite.httpClient)
+	suite.Require().NoError(err)
+
+	gock.New(testDummyAuthURL).
+		Post("/auth/tokens").
+		MatchType("json").
+		JSON(map[string]any{
+			"auth": map[string]any{
+				"identity": map[string]any{
+					"methods": []string{"application_credential"},
+					"application_credential": map[string]any{
+						"id":     "app-id",
+						"secret": "app-secret",
+					},
+				},
+			},
+		}).
+		Reply(http.StatusCreated).
+		SetHeader(subjectTokenHeader, "app-token").
+		JSON(map[string]any{
+			"token": map[string]any{"expires_at": time.Now().Add(time.Hour).Format(time.RFC3339)},
+		})
+
+	token, err := at.Token(context.Background())
+	suite.Require().NoError(err)
+	suite.Equal("app-token", token)
+}
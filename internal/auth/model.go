@@ -0,0 +1,72 @@
+package auth
+
+import "time"
+
+// authRequest — body of a request
+// POST /auth/tokens.
+type authRequest struct {
+	Auth authRequestAuth `json:"auth"`
+}
+
+type authRequestAuth struct {
+	Identity authIdentity `json:"identity"`
+	Scope    *authScope   `json:"scope,omitempty"`
+}
+
+type authIdentity struct {
+	Methods               []string                       `json:"methods"`
+	Password              *passwordIdentity              `json:"password,omitempty"`
+	ApplicationCredential *applicationCredentialIdentity `json:"application_credential,omitempty"`
+}
+
+type passwordIdentity struct {
+	User authUser `json:"user"`
+}
+
+type applicationCredentialIdentity struct {
+	ID     string `json:"id"`
+	Secret string `json:"secret"`
+}
+
+type authUser struct {
+	Name     string      `json:"name"`
+	Password string      `json:"password"`
+	Domain   *authDomain `json:"domain,omitempty"`
+}
+
+type authDomain struct {
+	Name string `json:"name"`
+}
+
+type authScope struct {
+	Project *authProject `json:"project,omitempty"`
+}
+
+type authProject struct {
+	ID     string      `json:"id,omitempty"`
+	Name   string      `json:"name,omitempty"`
+	Domain *authDomain `json:"domain,omitempty"`
+}
+
+// authResponse — entity received when making a request
+// POST /auth/tokens.
+type authResponse struct {
+	Token authToken `json:"token"`
+}
+
+type authToken struct {
+	ExpiresAt time.Time        `json:"expires_at"`
+	Catalog   []catalogService `json:"catalog"`
+}
+
+type catalogService struct {
+	Type      string            `json:"type"`
+	Endpoints []catalogEndpoint `json:"endpoints"`
+}
+
+type catalogEndpoint struct {
+	Interface string `json:"interface"`
+	Region    string `json:"region"`
+	RegionID  string `json:"region_id"`
+	URL       string `json:"url"`
+}
@@ -0,0 +1,191 @@
+// Package cache implements a read-through in-memory cache of responses of Secrets Manager API.
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+const (
+	// loadTimeout limits a load, it isn't canceled with ctx of the caller, that has started it,
+	// as other callers may wait for the same load.
+	loadTimeout = time.Minute
+
+	// sweepInterval is how often entries, that are too old to be served, are dropped.
+	sweepInterval = time.Minute
+)
+
+// Options — lifetimes of cached entries.
+type Options struct {
+	// TTL is how long a value is served without requests to the API.
+	TTL time.Duration
+
+	// NegativeTTL is how long a NOT_FOUND error is served without requests to the API, 0 disables negative caching.
+	NegativeTTL time.Duration
+
+	// StaleWhileRevalidate is how long an expired entry is still served, while it is refreshed in the background.
+	// 0 disables serving stale entries, an expired entry is loaded synchronously.
+	StaleWhileRevalidate time.Duration
+}
+
+type entry[V any] struct {
+	value     V
+	err       error // Only NOT_FOUND errors are cached.
+	expiresAt time.Time
+	staleAt   time.Time // expiresAt plus StaleWhileRevalidate.
+}
+
+// flight — loads of a key in progress, generation is bumped by Invalidate,
+// so loads started before it don't store outdated values.
+type flight struct {
+	loads      int
+	generation uint64
+}
+
+// Cache is a read-through cache, concurrent misses of the same key are loaded once.
+// It is safe for concurrent use.
+type Cache[V any] struct {
+	opts  Options
+	now   func() time.Time
+	group singleflight.Group
+
+	mu        sync.Mutex
+	entries   map[string]entry[V]
+	flights   map[string]*flight // Only keys, that are being loaded.
+	lastSweep time.Time
+}
+
+// New returns a Cache with the given lifetimes of entries.
+func New[V any](opts Options) *Cache[V] {
+	return &Cache[V]{
+		opts:    opts,
+		now:     time.Now,
+		entries: map[string]entry[V]{},
+		flights: map[string]*flight{},
+	}
+}
+
+// Get returns a cached value of a key or loads it with load.
+// A load is shared by concurrent callers, so it runs with a context, that keeps values of ctx,
+// but is not canceled with it and is limited by loadTimeout; each caller stops waiting, when its ctx is done.
+// An expired entry within StaleWhileRevalidate is returned at once and is refreshed in the background.
+func (c *Cache[V]) Get(ctx context.Context, key string, load func(ctx context.Context) (V, error)) (V, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	now := c.now()
+	c.mu.Unlock()
+
+	switch {
+	case ok && now.Before(e.expiresAt):
+		return e.value, e.err
+	case ok && now.Before(e.staleAt):
+		// The result is stored in the cache, so nobody waits for it.
+		c.group.DoChan(key, c.loader(ctx, key, load))
+		return e.value, e.err
+	}
+
+	select {
+	case res := <-c.group.DoChan(key, c.loader(ctx, key, load)):
+		value, _ := res.Val.(V)
+		return value, res.Err
+	case <-ctx.Done():
+		var value V
+		return value, secretsmanagererrors.Error{
+			Err:         secretsmanagererrors.ErrCannotDoRequest,
+			Desc:        "stopped waiting for " + key + ": " + ctx.Err().Error(),
+			Cause:       ctx.Err(),
+			ContextDone: true,
+		}
+	}
+}
+
+// Invalidate drops a cached entry of a key, loads of it, that are in flight, are not stored.
+func (c *Cache[V]) Invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+	if f, ok := c.flights[key]; ok {
+		f.generation++
+	}
+	c.group.Forget(key)
+}
+
+// loader returns a function, that loads a value of a key and stores it, it is called once for concurrent callers.
+func (c *Cache[V]) loader(
+	ctx context.Context, key string, load func(ctx context.Context) (V, error),
+) func() (any, error) {
+	return func() (any, error) {
+		c.mu.Lock()
+		f, ok := c.flights[key]
+		if !ok {
+			f = &flight{}
+			c.flights[key] = f
+		}
+		f.loads++
+		generation := f.generation
+		c.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+
+		value, err := load(ctx)
+		c.store(key, f, generation, value, err)
+		return value, err //nolint:wrapcheck // load already wraps the error.
+	}
+}
+
+func (c *Cache[V]) store(key string, f *flight, generation uint64, value V, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f.loads--
+	if f.loads == 0 {
+		delete(c.flights, key)
+	}
+
+	now := c.now()
+	c.sweep(now)
+
+	if f.generation != generation {
+		return
+	}
+
+	ttl := c.opts.TTL
+	if err != nil {
+		if !errors.Is(err, secretsmanagererrors.ErrNotFoundStatusText) || c.opts.NegativeTTL <= 0 {
+			// Other errors are not cached, a stale entry is served until it is refreshed or is too old.
+			return
+		}
+		ttl = c.opts.NegativeTTL
+	}
+
+	expiresAt := now.Add(ttl)
+	c.entries[key] = entry[V]{
+		value:     value,
+		err:       err,
+		expiresAt: expiresAt,
+		staleAt:   expiresAt.Add(c.opts.StaleWhileRevalidate),
+	}
+}
+
+// sweep drops entries, that are too old to be served, at most once per sweepInterval.
+// It must be called with mu locked.
+func (c *Cache[V]) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < sweepInterval {
+		return
+	}
+	c.lastSweep = now
+
+	for key, e := range c.entries {
+		if !now.Before(e.staleAt) {
+			delete(c.entries, key)
+		}
+	}
+}
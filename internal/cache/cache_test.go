@@ -0,0 +1,243 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+// fakeClock is a manually advanced clock.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestCache(opts Options) (*Cache[string], *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c := New[string](opts)
+	c.now = clock.Now
+	return c, clock
+}
+
+// counting returns a load function, that returns value and counts calls.
+func counting(calls *atomic.Int32, value string, err error) func(context.Context) (string, error) {
+	return func(context.Context) (string, error) {
+		calls.Add(1)
+		return value, err
+	}
+}
+
+func TestGetServesFreshEntry(t *testing.T) {
+	c, clock := newTestCache(Options{TTL: time.Minute})
+	var calls atomic.Int32
+
+	for range 3 {
+		value, err := c.Get(context.Background(), "key", counting(&calls, "v1", nil))
+		require.NoError(t, err)
+		require.Equal(t, "v1", value)
+	}
+	require.EqualValues(t, 1, calls.Load())
+
+	clock.Advance(time.Minute)
+	value, err := c.Get(context.Background(), "key", counting(&calls, "v2", nil))
+	require.NoError(t, err)
+	require.Equal(t, "v2", value)
+	require.EqualValues(t, 2, calls.Load())
+}
+
+func TestGetCachesNotFound(t *testing.T) {
+	notFound := secretsmanagererrors.Error{Err: secretsmanagererrors.ErrNotFoundStatusText}
+	c, clock := newTestCache(Options{TTL: time.Minute, NegativeTTL: time.Second})
+	var calls atomic.Int32
+
+	for range 2 {
+		_, err := c.Get(context.Background(), "key", counting(&calls, "", notFound))
+		require.ErrorIs(t, err, secretsmanagererrors.ErrNotFoundStatusText)
+	}
+	require.EqualValues(t, 1, calls.Load())
+
+	clock.Advance(time.Second)
+	value, err := c.Get(context.Background(), "key", counting(&calls, "v1", nil))
+	require.NoError(t, err)
+	require.Equal(t, "v1", value)
+	require.EqualValues(t, 2, calls.Load())
+}
+
+func TestGetDoesNotCacheOtherErrors(t *testing.T) {
+	internalErr := secretsmanagererrors.Error{Err: secretsmanagererrors.ErrInternalErrorStatusText}
+	c, _ := newTestCache(Options{TTL: time.Minute, NegativeTTL: time.Minute})
+	var calls atomic.Int32
+
+	for range 2 {
+		_, err := c.Get(context.Background(), "key", counting(&calls, "", internalErr))
+		require.ErrorIs(t, err, secretsmanagererrors.ErrInternalErrorStatusText)
+	}
+	require.EqualValues(t, 2, calls.Load())
+}
+
+func TestGetServesStaleWhileRevalidating(t *testing.T) {
+	c, clock := newTestCache(Options{TTL: time.Minute, StaleWhileRevalidate: time.Minute})
+
+	_, err := c.Get(context.Background(), "key", func(context.Context) (string, error) { return "v1", nil })
+	require.NoError(t, err)
+
+	clock.Advance(time.Minute + time.Second)
+
+	refreshed := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	value, err := c.Get(ctx, "key", func(ctx context.Context) (string, error) {
+		defer close(refreshed)
+		// The refresh outlives the request, that has started it.
+		assert.NoError(t, ctx.Err())
+		return "v2", nil
+	})
+	cancel()
+	require.NoError(t, err)
+	require.Equal(t, "v1", value)
+
+	<-refreshed
+	require.Eventually(t, func() bool {
+		value, err := c.Get(context.Background(), "key", func(context.Context) (string, error) {
+			return "", errors.New("unexpected load")
+		})
+		return err == nil && value == "v2"
+	}, time.Second, time.Millisecond)
+}
+
+func TestGetLoadsConcurrentMissesOnce(t *testing.T) {
+	c, _ := newTestCache(Options{TTL: time.Minute})
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	const callers = 10
+	var wg sync.WaitGroup
+	values := make([]string, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], _ = c.Get(context.Background(), "key", func(context.Context) (string, error) {
+				calls.Add(1)
+				<-release
+				return "v1", nil
+			})
+		}()
+	}
+
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+	// Let other callers join the load in flight.
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.EqualValues(t, 1, calls.Load())
+	for _, value := range values {
+		require.Equal(t, "v1", value)
+	}
+}
+
+func TestInvalidateDiscardsLoadInFlight(t *testing.T) {
+	c, _ := newTestCache(Options{TTL: time.Minute})
+	started, release := make(chan struct{}), make(chan struct{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		value, err := c.Get(context.Background(), "key", func(context.Context) (string, error) {
+			close(started)
+			<-release
+			return "outdated", nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "outdated", value)
+	}()
+
+	<-started
+	c.Invalidate("key")
+	close(release)
+	<-done
+
+	var calls atomic.Int32
+	value, err := c.Get(context.Background(), "key", counting(&calls, "v2", nil))
+	require.NoError(t, err)
+	require.Equal(t, "v2", value)
+	require.EqualValues(t, 1, calls.Load())
+}
+
+func TestGetCanceledCallerDoesNotCancelLoad(t *testing.T) {
+	c, _ := newTestCache(Options{TTL: time.Minute})
+	started, release := make(chan struct{}), make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error)
+	go func() {
+		_, err := c.Get(ctx, "key", func(ctx context.Context) (string, error) {
+			close(started)
+			<-release
+			// The load outlives the caller, that has started it.
+			assert.NoError(t, ctx.Err())
+			return "v1", nil
+		})
+		canceled <- err
+	}()
+
+	<-started
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		value, err := c.Get(context.Background(), "key", func(context.Context) (string, error) {
+			return "", errors.New("unexpected load")
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "v1", value)
+	}()
+
+	cancel()
+	err := <-canceled
+	require.ErrorIs(t, err, secretsmanagererrors.ErrCannotDoRequest)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// Let the second caller join the load in flight.
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	<-done
+}
+
+func TestSweepDropsOldEntries(t *testing.T) {
+	c, clock := newTestCache(Options{TTL: time.Minute, StaleWhileRevalidate: time.Minute})
+	var calls atomic.Int32
+
+	_, err := c.Get(context.Background(), "old", counting(&calls, "v1", nil))
+	require.NoError(t, err)
+	c.Invalidate("invalidated")
+
+	clock.Advance(2 * time.Minute)
+	_, err = c.Get(context.Background(), "new", counting(&calls, "v1", nil))
+	require.NoError(t, err)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	require.NotContains(t, c.entries, "old")
+	require.Contains(t, c.entries, "new")
+	require.Empty(t, c.flights)
+}
@@ -0,0 +1,150 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/selectel/secretsmanager-go/middleware"
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+// requestIDHeaders — headers, in which the backend returns an ID of a request, in order of preference.
+//
+//nolint:gochecknoglobals
+var requestIDHeaders = []string{"X-Request-Id", "X-Openstack-Request-Id"}
+
+// withRequest fills a secretsmanagererrors.Error with a method, an endpoint,
+// an HTTP status and a request ID of a failed request.
+// resp is nil, if no response has been received.
+func withRequest(err error, req *middleware.Request, resp *middleware.Response) error {
+	var smErr secretsmanagererrors.Error
+	if !errors.As(err, &smErr) {
+		return err
+	}
+
+	smErr.Method = req.Method
+	smErr.Endpoint = redactEndpoint(req.URL)
+
+	if resp != nil {
+		smErr.StatusCode = resp.StatusCode
+		smErr.RequestID = requestID(resp.Header)
+	}
+
+	return smErr
+}
+
+// redactEndpoint drops credentials, a query and a fragment from a URL,
+// so it is safe to be shown in errors.
+func redactEndpoint(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	return u.String()
+}
+
+// requestID returns an ID of a request assigned by the backend.
+func requestID(header http.Header) string {
+	for _, name := range requestIDHeaders {
+		if id := header.Get(name); len(id) > 0 {
+			return id
+		}
+	}
+
+	return ""
+}
+
+// maxErrorSnippetLen is a maximum number of characters of an unexpected response body kept in an error.
+const maxErrorSnippetLen = 256
+
+// errorFromStatus returns an error for a response, that has no valid ErrResponse body,
+// classified by its HTTP status; a snippet of the body is kept in a description for diagnostics.
+func errorFromStatus(statusCode int, body []byte) error {
+	desc := http.StatusText(statusCode)
+	if snippet := bodySnippet(body); len(snippet) > 0 {
+		desc = fmt.Sprintf("%s: unexpected response body: %s", desc, snippet)
+	}
+
+	return secretsmanagererrors.Error{
+		Err:  statusToError(statusCode),
+		Desc: desc,
+	}
+}
+
+// statusToError maps an HTTP status to a sentinel error.
+func statusToError(statusCode int) error {
+	switch {
+	case statusCode == http.StatusBadRequest:
+		return secretsmanagererrors.ErrBadRequestStatusText
+	case statusCode == http.StatusUnauthorized:
+		return secretsmanagererrors.ErrUnauthorizedStatusText
+	case statusCode == http.StatusForbidden:
+		return secretsmanagererrors.ErrForbiddenStatusText
+	case statusCode == http.StatusNotFound:
+		return secretsmanagererrors.ErrNotFoundStatusText
+	case statusCode == http.StatusMethodNotAllowed:
+		return secretsmanagererrors.ErrMethodNotAllowed
+	case statusCode == http.StatusConflict:
+		return secretsmanagererrors.ErrConflictStatusText
+	case statusCode == http.StatusTooManyRequests:
+		return secretsmanagererrors.ErrTooManyRequestsText
+	case statusCode >= http.StatusInternalServerError:
+		return secretsmanagererrors.ErrInternalErrorStatusText
+	default:
+		return secretsmanagererrors.ErrUnknown
+	}
+}
+
+// bodySnippet returns a printable single-line text of a body without HTML tags,
+// truncated to maxErrorSnippetLen characters.
+func bodySnippet(body []byte) string {
+	var (
+		sb    strings.Builder
+		inTag bool
+		space bool
+		n     int
+	)
+
+	for _, r := range string(body) {
+		switch {
+		case r == '<':
+			inTag = true
+			continue
+		case r == '>' && inTag:
+			inTag = false
+			space = true
+			continue
+		case inTag:
+			continue
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		case !unicode.IsPrint(r):
+			continue
+		}
+
+		if n == maxErrorSnippetLen {
+			sb.WriteString("...")
+			break
+		}
+
+		if space && sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		space = false
+
+		sb.WriteRune(r)
+		n++
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,27 @@
+package httpclient
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodySnippet(t *testing.T) {
+	tests := map[string]struct {
+		body string
+		want string
+	}{
+		"Empty":      {"", ""},
+		"Plain Text": {"  upstream\r\n\tconnect error  ", "upstream connect error"},
+		"HTML":       {"<h1>Service</h1><p>Unavailable</p>", "Service Unavailable"},
+		"Control":    {"bad\x00\x1bbody", "badbody"},
+		"Truncated":  {strings.Repeat("a", maxErrorSnippetLen+10), strings.Repeat("a", maxErrorSnippetLen) + "..."},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.want, bodySnippet([]byte(test.body)))
+		})
+	}
+}
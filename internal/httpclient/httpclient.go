@@ -1,14 +1,18 @@
 package httpclient
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/selectel/secretsmanager-go/internal/auth"
+	"github.com/selectel/secretsmanager-go/middleware"
 	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
 )
 
@@ -44,6 +48,26 @@ const ( // defaultHTTPTimeout represents the default timeout (in seconds) for HT
 type HTTPClient struct {
 	*http.Client
 	Auth auth.Type
+
+	// RetryPolicy defines how failed requests are retried, zero value disables retries.
+	RetryPolicy RetryPolicy
+
+	// RateLimiter limits a rate of requests, nil disables limiting.
+	RateLimiter *RateLimiter
+
+	// Middlewares wrap DoRequest, the first one is the outermost.
+	Middlewares []middleware.Middleware
+
+	// OperationObservers are notified by StartOperation, the first one is the outermost.
+	OperationObservers []middleware.OperationObserver
+
+	// Observer receives events of requests, nil disables observing.
+	Observer Observer
+
+	// Logger logs events of requests at LogLevels, nil disables logging.
+	// Neither headers nor bodies of requests and responses are logged.
+	Logger    *slog.Logger
+	LogLevels LogLevels
 }
 
 func New(auth auth.Type, httpClient *http.Client) *HTTPClient {
@@ -58,41 +82,296 @@ func New(auth auth.Type, httpClient *http.Client) *HTTPClient {
 }
 
 // DoRequest — is a helper method, to reduce repeated code.
-func (cl *HTTPClient) DoRequest(ctx context.Context, method, url string, body io.Reader) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, method, url, body)
+// Operation is a logical name of an SDK operation (like "secrets.Get") and resource is a secret key
+// or a certificate ID it is performed on, both are passed to Middlewares.
+// If the backend rejects a token and Auth is able to renew it,
+// the request is replayed once with a newly issued token.
+// Failed attempts are retried according to RetryPolicy.
+func (cl *HTTPClient) DoRequest(
+	ctx context.Context, operation, resource, method, url string, body io.Reader,
+) ([]byte, error) {
+	req := &middleware.Request{
+		Operation: operation,
+		Resource:  resource,
+		Method:    method,
+		URL:       url,
+		Header:    http.Header{},
+	}
+
+	if body != nil {
+		var err error
+		req.Body, err = io.ReadAll(body)
+		if err != nil {
+			return nil, secretsmanagererrors.Error{
+				Err:  secretsmanagererrors.ErrInternalAppError,
+				Desc: err.Error(),
+			}
+		}
+	}
+
+	handler := cl.handle
+	for i := len(cl.Middlewares) - 1; i >= 0; i-- {
+		handler = cl.Middlewares[i](handler)
+	}
+
+	resp, err := handler(ctx, req)
 	if err != nil {
-		return nil, secretsmanagererrors.Error{
-			Err:  secretsmanagererrors.ErrInternalAppError,
-			Desc: err.Error(),
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// StartOperation notifies OperationObservers, that an operation, that makes several requests, is started.
+// The returned context has to be passed to its requests and end has to be called with its result.
+func (cl *HTTPClient) StartOperation(ctx context.Context, name, resource string) (context.Context, func(err error)) {
+	op := middleware.Operation{Name: name, Resource: resource}
+
+	ends := make([]func(error), 0, len(cl.OperationObservers))
+	for _, observer := range cl.OperationObservers {
+		var end func(error)
+		ctx, end = observer.StartOperation(ctx, op)
+		ends = append(ends, end)
+	}
+
+	return ctx, func(err error) {
+		for i := len(ends) - 1; i >= 0; i-- {
+			ends[i](err)
 		}
 	}
+}
 
-	req.Header.Set("X-Auth-Token", cl.Auth.GetKeystoneToken())
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", userAgent)
+// handle is the innermost middleware.Handler, that sends a request to the backend.
+func (cl *HTTPClient) handle(ctx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	start := time.Now()
+	cl.onStart(ctx, req)
 
-	resp, err := cl.Do(req)
+	resp, err := cl.retry(ctx, req)
 	if err != nil {
-		return nil, secretsmanagererrors.Error{
-			Err:  secretsmanagererrors.ErrInternalAppError,
-			Desc: err.Error(),
+		err = withRequest(err, req, resp)
+	}
+
+	cl.onFinish(ctx, req, resp, err, time.Since(start))
+
+	return resp, err
+}
+
+// retry performs attempts of a request according to RetryPolicy.
+func (cl *HTTPClient) retry(ctx context.Context, req *middleware.Request) (*middleware.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, res, err := cl.attempt(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+
+		wait, retry := cl.RetryPolicy.next(ctx, req.Method, attempt, res)
+		if !retry {
+			return resp, withAttempts(err, attempt)
+		}
+
+		cl.onRetry(ctx, req, attempt, wait, err)
+
+		if ctxErr := sleep(ctx, wait); ctxErr != nil {
+			return resp, stoppedRetrying(err, ctxErr, attempt)
+		}
+	}
+}
+
+// onStart notifies Observer and Logger, that an operation is started.
+func (cl *HTTPClient) onStart(ctx context.Context, req *middleware.Request) {
+	if cl.Observer != nil {
+		cl.Observer.ObserveRequestStart(req.Operation)
+	}
+
+	if cl.Logger != nil {
+		cl.Logger.Log(ctx, cl.LogLevels.Start, "secretsmanager: request started", requestAttrs(req)...)
+	}
+}
+
+// onRetry notifies Observer and Logger, that a failed attempt is going to be retried.
+func (cl *HTTPClient) onRetry(
+	ctx context.Context, req *middleware.Request, attempt int, wait time.Duration, err error,
+) {
+	if cl.Observer != nil {
+		cl.Observer.ObserveRetry(req.Operation)
+	}
+
+	if cl.Logger != nil {
+		attrs := append(requestAttrs(req),
+			slog.Int("attempt", attempt),
+			slog.Duration("wait", wait),
+			slog.String("error", err.Error()),
+		)
+		cl.Logger.Log(ctx, cl.LogLevels.Retry, "secretsmanager: retrying request", attrs...)
+	}
+}
+
+// onFinish notifies Observer and Logger, that an operation is finished.
+func (cl *HTTPClient) onFinish(
+	ctx context.Context, req *middleware.Request, resp *middleware.Response, err error, duration time.Duration,
+) {
+	var statusCode int
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+
+	if cl.Observer != nil {
+		cl.Observer.ObserveRequest(req.Operation, statusCode, err, duration)
+	}
+
+	if cl.Logger == nil {
+		return
+	}
+
+	attrs := append(requestAttrs(req),
+		slog.Int("status", statusCode),
+		slog.Duration("duration", duration),
+	)
+
+	if err != nil {
+		attrs = append(attrs,
+			slog.String("error_code", secretsmanagererrors.Code(err)),
+			slog.String("error", err.Error()),
+		)
+		cl.Logger.Log(ctx, cl.LogLevels.Error, "secretsmanager: request failed", attrs...)
+		return
+	}
+
+	cl.Logger.Log(ctx, cl.LogLevels.Finish, "secretsmanager: request finished", attrs...)
+}
+
+// attempt performs a request once, replaying it with a new token, if the current one has been rejected.
+func (cl *HTTPClient) attempt(
+	ctx context.Context, req *middleware.Request,
+) (*middleware.Response, attemptResult, error) {
+	token, err := cl.token(ctx, cl.Auth)
+	if err != nil {
+		return nil, attemptResult{}, err
+	}
+
+	if cl.RateLimiter != nil {
+		err = cl.RateLimiter.Wait(ctx, req.Method)
+		if err != nil {
+			return nil, attemptResult{}, err
+		}
+	}
+
+	resp, err := cl.do(ctx, req, token)
+	if err != nil {
+		return nil, attemptResult{transportFailed: true}, err
+	}
+
+	if renewable, ok := cl.Auth.(auth.Renewable); ok && resp.StatusCode == http.StatusUnauthorized {
+		discardBody(resp)
+		renewable.InvalidateToken(token)
+
+		token, err = cl.token(ctx, renewable)
+		if err != nil {
+			return nil, attemptResult{}, err
+		}
+
+		resp, err = cl.do(ctx, req, token)
+		if err != nil {
+			return nil, attemptResult{transportFailed: true}, err
 		}
 	}
 	defer resp.Body.Close()
 
+	res := attemptResult{statusCode: resp.StatusCode, header: resp.Header}
+	if cl.RateLimiter != nil {
+		cl.RateLimiter.Observe(req.Method, resp.StatusCode)
+	}
+
 	err = hasBackendError(resp)
 	if err != nil {
-		return nil, err
+		return &middleware.Response{StatusCode: resp.StatusCode, Header: resp.Header}, res, err
 	}
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return []byte{}, secretsmanagererrors.Error{
+		return nil, attemptResult{transportFailed: true}, secretsmanagererrors.Error{
 			Err:  secretsmanagererrors.ErrCannotReadBody,
 			Desc: err.Error(),
 		}
 	}
-	return respBody, nil
+
+	return &middleware.Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       respBody,
+	}, res, nil
+}
+
+// token retrieves a token from an auth Type, errors of user-provided
+// implementations are wrapped into secretsmanagererrors.Error.
+func (cl *HTTPClient) token(ctx context.Context, at auth.Type) (string, error) {
+	token, err := at.Token(ctx)
+	if err != nil {
+		var smErr secretsmanagererrors.Error
+		if errors.As(err, &smErr) {
+			return "", err
+		}
+
+		return "", secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrCannotIssueAuthToken,
+			Desc: err.Error(),
+		}
+	}
+
+	if len(token) == 0 {
+		return "", secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrCannotIssueAuthToken,
+			Desc: "token provider returned an empty token",
+		}
+	}
+
+	return token, nil
+}
+
+// do performs a single attempt of a request, body is re-read on every call,
+// so the same request can be replayed.
+func (cl *HTTPClient) do(ctx context.Context, r *middleware.Request, token string) (*http.Response, error) {
+	var reqBody io.Reader
+	if r.Body != nil {
+		reqBody = bytes.NewReader(r.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.Method, r.URL, reqBody)
+	if err != nil {
+		return nil, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrInternalAppError,
+			Desc: err.Error(),
+		}
+	}
+
+	for name, values := range r.Header {
+		req.Header[name] = values
+	}
+
+	req.Header.Set("X-Auth-Token", token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	if key, ok := idempotencyKey(ctx); ok {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+
+	resp, err := cl.Do(req)
+	if err != nil {
+		return nil, secretsmanagererrors.Error{
+			Err:         secretsmanagererrors.ErrCannotDoRequest,
+			Desc:        err.Error(),
+			Cause:       err,
+			ContextDone: ctx.Err() != nil,
+		}
+	}
+
+	return resp, nil
+}
+
+// discardBody drains and closes a response body, so the connection can be reused.
+func discardBody(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
 }
 
 // hasBackendError is a helper function to returning an error from backend
@@ -114,22 +393,21 @@ func hasBackendError(resp *http.Response) error {
 			}
 		}
 
-		var er secretsmanagererrors.ErrResponse
+		er := secretsmanagererrors.ErrResponse{HTTPStatusCode: resp.StatusCode}
 		err = json.Unmarshal(errBodyText, &er)
-		if err != nil {
-			return secretsmanagererrors.Error{
-				Err:  secretsmanagererrors.ErrInternalAppError,
-				Desc: err.Error(),
-			}
+		if err != nil || len(er.StatusText) == 0 {
+			// Not an error of the backend itself, but an HTML page of a proxy or an empty body.
+			return errorFromStatus(resp.StatusCode, errBodyText)
 		}
 
 		if e := secretsmanagererrors.GetError(er.StatusText); e != nil {
-			return secretsmanagererrors.Error{Err: e, Desc: er.ErrorText}
+			return secretsmanagererrors.Error{Err: e, Desc: er.ErrorText, Response: &er}
 		}
 
 		return secretsmanagererrors.Error{
-			Err:  secretsmanagererrors.ErrUnknown,
-			Desc: fmt.Sprintf("%s -- %s", er.StatusText, er.StatusText),
+			Err:      secretsmanagererrors.ErrUnknown,
+			Desc:     fmt.Sprintf("%s -- %s", er.StatusText, er.ErrorText),
+			Response: &er,
 		}
 	}
 	return nil
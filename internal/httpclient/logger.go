@@ -0,0 +1,43 @@
+package httpclient
+
+import (
+	"log/slog"
+
+	"github.com/selectel/secretsmanager-go/middleware"
+)
+
+// LogLevels — levels, at which events of requests are logged.
+type LogLevels struct {
+	Start  slog.Level
+	Finish slog.Level
+	Retry  slog.Level
+	Error  slog.Level
+}
+
+// DefaultLogLevels returns LogLevels, that log start and finish at Debug,
+// retries at Warn and failures at Error level.
+func DefaultLogLevels() LogLevels {
+	return LogLevels{
+		Start:  slog.LevelDebug,
+		Finish: slog.LevelDebug,
+		Retry:  slog.LevelWarn,
+		Error:  slog.LevelError,
+	}
+}
+
+// requestAttrs returns attributes describing a request, that are safe to be logged:
+// neither headers (with X-Auth-Token) nor bodies (with secret values) are included,
+// credentials and a query are removed from URL.
+func requestAttrs(req *middleware.Request) []any {
+	attrs := []any{
+		slog.String("operation", req.Operation),
+		slog.String("method", req.Method),
+		slog.String("url", redactEndpoint(req.URL)),
+	}
+
+	if len(req.Resource) > 0 {
+		attrs = append(attrs, slog.String("resource", req.Resource))
+	}
+
+	return attrs
+}
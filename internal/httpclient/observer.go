@@ -0,0 +1,17 @@
+package httpclient
+
+import "time"
+
+// Observer — receives events of requests, it is used to collect metrics.
+// It has the same method set as the public secretsmanager.MetricsCollector.
+type Observer interface {
+	// ObserveRequestStart is called before the first attempt of an operation.
+	ObserveRequestStart(operation string)
+
+	// ObserveRetry is called before every retry of an operation.
+	ObserveRetry(operation string)
+
+	// ObserveRequest is called when an operation is finished, statusCode is 0
+	// if no response has been received, err is nil on success.
+	ObserveRequest(operation string, statusCode int, err error, duration time.Duration)
+}
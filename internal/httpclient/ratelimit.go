@@ -0,0 +1,172 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+const (
+	// throttleFactor represents how much the rate is reduced after the backend returned 429.
+	throttleFactor = 0.5
+
+	// recoverySteps represents in how many successful requests the rate is restored after throttling.
+	recoverySteps = 10
+
+	// minRateFraction represents the lowest fraction of the configured rate, the limiter can be throttled to.
+	minRateFraction = 1.0 / 16
+
+	// throttleCooldown represents how often the rate can be reduced,
+	// so a burst of concurrent 429 responses doesn't collapse it.
+	throttleCooldown = time.Second
+)
+
+// RateLimit — a budget of requests, that are allowed to be made.
+type RateLimit struct {
+	// RequestsPerSecond is a sustained rate of requests.
+	RequestsPerSecond float64
+
+	// Burst is a maximum number of requests, that can be made at once,
+	// it is at least 1.
+	Burst int
+}
+
+// RateLimiter — a token bucket limiter shared by all services of a client.
+// Every request waits for both the client-wide and the HTTP method budget.
+// The rate is reduced, when the backend returns 429, and restored gradually afterwards.
+type RateLimiter struct {
+	global    *adaptiveLimiter
+	perMethod map[string]*adaptiveLimiter
+}
+
+// NewRateLimiter returns a RateLimiter with the client-wide limit and optional per HTTP method budgets.
+// Zero RequestsPerSecond in the global limit means no client-wide limit.
+func NewRateLimiter(global RateLimit, perMethod map[string]RateLimit) *RateLimiter {
+	rl := &RateLimiter{
+		perMethod: make(map[string]*adaptiveLimiter, len(perMethod)),
+	}
+
+	if global.RequestsPerSecond > 0 {
+		rl.global = newAdaptiveLimiter(global)
+	}
+
+	for method, limit := range perMethod {
+		if limit.RequestsPerSecond > 0 {
+			rl.perMethod[method] = newAdaptiveLimiter(limit)
+		}
+	}
+
+	return rl
+}
+
+// Wait blocks until a request with the given method is allowed or ctx is done.
+func (rl *RateLimiter) Wait(ctx context.Context, method string) error {
+	for _, al := range rl.limiters(method) {
+		err := al.limiter.Wait(ctx)
+		if err != nil {
+			cause := waitCause(ctx, err)
+			return secretsmanagererrors.Error{
+				Err:   secretsmanagererrors.ErrCannotDoRequest,
+				Desc:  "waiting for rate limiter: " + err.Error(),
+				Cause: cause,
+				// Errors of the limiter itself aren't errors of context.
+				ContextDone: errors.Is(cause, context.Canceled) || errors.Is(cause, context.DeadlineExceeded),
+			}
+		}
+	}
+
+	return nil
+}
+
+// waitCause returns an error of ctx, that has made Wait fail. The limiter fails at once,
+// if a wait wouldn't fit into the deadline of ctx, so that case is reported as context.DeadlineExceeded.
+func waitCause(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	if _, ok := ctx.Deadline(); ok {
+		return context.DeadlineExceeded
+	}
+
+	return err
+}
+
+// Observe adapts the rate according to a status code of a finished request.
+func (rl *RateLimiter) Observe(method string, statusCode int) {
+	for _, al := range rl.limiters(method) {
+		if statusCode == 429 { //nolint:gomnd
+			al.throttle()
+		} else {
+			al.recover()
+		}
+	}
+}
+
+func (rl *RateLimiter) limiters(method string) []*adaptiveLimiter {
+	limiters := make([]*adaptiveLimiter, 0, 2) //nolint:gomnd
+	if rl.global != nil {
+		limiters = append(limiters, rl.global)
+	}
+	if al, ok := rl.perMethod[method]; ok {
+		limiters = append(limiters, al)
+	}
+
+	return limiters
+}
+
+// adaptiveLimiter — a token bucket, which rate is changed between
+// a fraction of the configured one and the configured one.
+type adaptiveLimiter struct {
+	limiter *rate.Limiter
+	max     rate.Limit
+	min     rate.Limit
+
+	mu          sync.Mutex
+	throttledAt time.Time
+}
+
+func newAdaptiveLimiter(limit RateLimit) *adaptiveLimiter {
+	burst := limit.Burst
+	if burst < 1 {
+		burst = 1
+	}
+
+	maxRate := rate.Limit(limit.RequestsPerSecond)
+
+	return &adaptiveLimiter{
+		limiter: rate.NewLimiter(maxRate, burst),
+		max:     maxRate,
+		min:     maxRate * minRateFraction,
+	}
+}
+
+func (al *adaptiveLimiter) throttle() {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(al.throttledAt) < throttleCooldown {
+		return
+	}
+	al.throttledAt = now
+
+	al.limiter.SetLimitAt(now, max(al.limiter.Limit()*throttleFactor, al.min))
+}
+
+func (al *adaptiveLimiter) recover() {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	current := al.limiter.Limit()
+	if current >= al.max {
+		return
+	}
+
+	al.limiter.SetLimit(min(current+al.max/recoverySteps, al.max))
+}
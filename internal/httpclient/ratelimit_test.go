@@ -0,0 +1,61 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+func TestRateLimiterAdapts(t *testing.T) {
+	rl := NewRateLimiter(RateLimit{RequestsPerSecond: 100, Burst: 1}, nil)
+
+	rl.Observe(http.MethodGet, http.StatusTooManyRequests)
+	require.Equal(t, rate.Limit(50), rl.global.limiter.Limit())
+
+	// Concurrent 429 responses within the cooldown reduce the rate only once.
+	rl.Observe(http.MethodGet, http.StatusTooManyRequests)
+	require.Equal(t, rate.Limit(50), rl.global.limiter.Limit())
+
+	for i := 0; i < recoverySteps; i++ {
+		rl.Observe(http.MethodGet, http.StatusOK)
+	}
+	require.Equal(t, rate.Limit(100), rl.global.limiter.Limit())
+}
+
+func TestRateLimiterPerMethod(t *testing.T) {
+	rl := NewRateLimiter(RateLimit{}, map[string]RateLimit{
+		http.MethodPost: {RequestsPerSecond: 0.001, Burst: 1},
+	})
+
+	ctx := context.Background()
+	require.NoError(t, rl.Wait(ctx, http.MethodGet))
+	require.NoError(t, rl.Wait(ctx, http.MethodPost))
+
+	// The budget of POST requests is exhausted, so the next one waits until ctx is done.
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	err := rl.Wait(ctx, http.MethodPost)
+	require.ErrorIs(t, err, secretsmanagererrors.ErrCannotDoRequest)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.False(t, secretsmanagererrors.Retryable(err))
+	require.NoError(t, rl.Wait(ctx, http.MethodGet))
+}
+
+func TestRateLimiterCanceled(t *testing.T) {
+	rl := NewRateLimiter(RateLimit{RequestsPerSecond: 0.001, Burst: 1}, nil)
+	require.NoError(t, rl.Wait(context.Background(), http.MethodGet))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := rl.Wait(ctx, http.MethodGet)
+	require.ErrorIs(t, err, context.Canceled)
+	require.False(t, secretsmanagererrors.Retryable(err))
+}
@@ -0,0 +1,184 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+// idempotencyKeyHeader is a header used to pass an idempotency key of a request.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// RetryPolicy — defines how failed requests are retried.
+// Requests are retried on network errors and on 429, 502, 503 and 504 statuses.
+// Non-idempotent requests (POST) are retried only if they carry an idempotency key,
+// were rejected with 429 before being processed, or RetryNonIdempotent is set.
+type RetryPolicy struct {
+	// MaxAttempts is a maximum number of attempts including the first one,
+	// values less than 2 disable retries.
+	MaxAttempts int
+
+	// InitialBackoff is a delay before the first retry, it is doubled for each next retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff limits a delay between retries, zero means no limit.
+	// If Retry-After asks to wait longer, the request is not retried.
+	MaxBackoff time.Duration
+
+	// RetryNonIdempotent allows to retry non-idempotent requests without an idempotency key.
+	RetryNonIdempotent bool
+}
+
+// attemptResult — describes an outcome of a single attempt, that is used to decide whether to retry.
+type attemptResult struct {
+	statusCode      int
+	header          http.Header
+	transportFailed bool
+}
+
+// next returns a delay before the next attempt and whether it should be made at all.
+func (rp RetryPolicy) next(ctx context.Context, method string, attempt int, res attemptResult) (time.Duration, bool) {
+	if attempt >= rp.MaxAttempts || ctx.Err() != nil {
+		return 0, false
+	}
+
+	switch {
+	case res.transportFailed:
+	case res.statusCode == http.StatusTooManyRequests,
+		res.statusCode == http.StatusBadGateway,
+		res.statusCode == http.StatusServiceUnavailable,
+		res.statusCode == http.StatusGatewayTimeout:
+	default:
+		return 0, false
+	}
+
+	_, hasKey := idempotencyKey(ctx)
+	if !isIdempotent(method) && !rp.RetryNonIdempotent && !hasKey && res.statusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	wait, ok := retryAfter(res.header, time.Now())
+	switch {
+	case !ok:
+		wait = rp.backoff(attempt)
+	case rp.MaxBackoff > 0 && wait > rp.MaxBackoff:
+		// Retrying earlier than the backend asks would be rejected again.
+		return 0, false
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+		return 0, false
+	}
+
+	return wait, true
+}
+
+// backoff returns an exponential delay with jitter for the given attempt:
+// a random value between a half and a full exponential delay.
+func (rp RetryPolicy) backoff(attempt int) time.Duration {
+	delay := rp.InitialBackoff
+	for i := 1; i < attempt && (rp.MaxBackoff == 0 || delay < rp.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if rp.MaxBackoff > 0 && delay > rp.MaxBackoff {
+		delay = rp.MaxBackoff
+	}
+
+	half := int64(delay / 2) //nolint:gomnd
+	if half <= 0 {
+		return delay
+	}
+
+	return time.Duration(half + rand.Int63n(half+1)) //nolint:gosec // Jitter doesn't need a secure random.
+}
+
+// retryAfter parses Retry-After header, that contains either seconds or an HTTP date.
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if len(value) == 0 {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleep waits for the given duration or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck // The error is not returned to user.
+	case <-timer.C:
+		return nil
+	}
+}
+
+// withAttempts adds a number of made attempts to the description of an error.
+func withAttempts(err error, attempts int) error {
+	if attempts < 2 { //nolint:gomnd
+		return err
+	}
+
+	var smErr secretsmanagererrors.Error
+	if !errors.As(err, &smErr) {
+		return err
+	}
+
+	smErr.Desc = fmt.Sprintf("%s (gave up after %d attempts)", smErr.Desc, attempts)
+	return smErr
+}
+
+// stoppedRetrying marks an error of the last attempt, as ctx has been done, while waiting for the next one:
+// the error of ctx becomes its Cause, so it isn't retryable and matches context.Canceled.
+func stoppedRetrying(err, ctxErr error, attempts int) error {
+	var smErr secretsmanagererrors.Error
+	if !errors.As(err, &smErr) {
+		return err
+	}
+
+	smErr.Desc = fmt.Sprintf("%s (stopped waiting for attempt %d: %s)", smErr.Desc, attempts+1, ctxErr)
+	smErr.Cause = ctxErr
+	smErr.ContextDone = true
+	return smErr
+}
+
+type idempotencyKeyCtx struct{}
+
+// WithIdempotencyKey returns a context, requests made with which carry the given idempotency key,
+// so non-idempotent ones can be retried safely.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtx{}, key)
+}
+
+func idempotencyKey(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyCtx{}).(string)
+	return key, ok && len(key) > 0
+}
@@ -0,0 +1,77 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		value   string
+		expWait time.Duration
+		expOK   bool
+	}{
+		"Seconds":   {"3", 3 * time.Second, true},
+		"HTTP Date": {now.Add(time.Minute).Format(http.TimeFormat), time.Minute, true},
+		"Past Date": {now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		"Missing":   {"", 0, false},
+		"Malformed": {"soon", 0, false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			if len(test.value) > 0 {
+				header.Set("Retry-After", test.value)
+			}
+
+			wait, ok := retryAfter(header, now)
+			require.Equal(t, test.expOK, ok)
+			require.Equal(t, test.expWait, wait)
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	rp := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	for attempt, expMax := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		5: time.Second,
+		9: time.Second,
+	} {
+		got := rp.backoff(attempt)
+		require.GreaterOrEqual(t, got, expMax/2)
+		require.LessOrEqual(t, got, expMax)
+	}
+}
+
+func TestNextRetryAfter(t *testing.T) {
+	rp := RetryPolicy{MaxAttempts: 3, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 5 * time.Second}
+	res := func(retryAfter string) attemptResult {
+		return attemptResult{
+			statusCode: http.StatusTooManyRequests,
+			header:     http.Header{"Retry-After": []string{retryAfter}},
+		}
+	}
+
+	wait, ok := rp.next(context.Background(), http.MethodGet, 1, res("2"))
+	require.True(t, ok)
+	require.Equal(t, 2*time.Second, wait)
+
+	_, ok = rp.next(context.Background(), http.MethodGet, 1, res("86400"))
+	require.False(t, ok)
+
+	rp.MaxBackoff = 0
+	wait, ok = rp.next(context.Background(), http.MethodGet, 1, res("86400"))
+	require.True(t, ok)
+	require.Equal(t, 24*time.Hour, wait)
+}
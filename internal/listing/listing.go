@@ -0,0 +1,139 @@
+// Package listing implements lazy iterators over list responses of Secrets Manager API.
+package listing
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+// Fetch returns the next chunk of items after a cursor and a cursor of the chunk after it,
+// an empty cursor is passed for the first chunk and returned after the last one.
+// Endpoints without server-side pagination return everything in a single chunk.
+type Fetch[T any] func(ctx context.Context, cursor string) (items []T, next string, err error)
+
+// Options — filtering and sorting of iterated items.
+type Options[T any] struct {
+	// Keep reports whether an item has to be yielded, nil keeps all items.
+	Keep func(item T) (bool, error)
+
+	// Compare sorts items, if it is set. All chunks are fetched before the first item is yielded,
+	// as items can't be sorted otherwise.
+	Compare func(a, b T) int
+}
+
+// Seq returns an iterator, that fetches chunks lazily, when the iteration reaches them.
+// The iteration stops after the first error, which is yielded with a zero item;
+// it stops with an error as well, if ctx is done.
+func Seq[T any](ctx context.Context, fetch Fetch[T], opts Options[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		filtered := chunks(ctx, fetch, opts.Keep)
+		if opts.Compare != nil {
+			var all []T
+			for item, err := range filtered {
+				if err != nil {
+					yield(zero, err)
+					return
+				}
+				all = append(all, item)
+			}
+			slices.SortStableFunc(all, opts.Compare)
+
+			filtered = fromSlice(ctx, all)
+		}
+
+		for item, err := range filtered {
+			if !yield(item, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// chunks yields kept items of chunks one by one, a next chunk is fetched, when the current one is exhausted.
+func chunks[T any](ctx context.Context, fetch Fetch[T], keep func(T) (bool, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var (
+			zero   T
+			cursor string
+		)
+
+		for {
+			if err := ctxErr(ctx); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			items, next, err := fetch(ctx, cursor)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if err := ctxErr(ctx); err != nil {
+					yield(zero, err)
+					return
+				}
+
+				if keep != nil {
+					ok, err := keep(item)
+					if err != nil {
+						yield(zero, err)
+						return
+					}
+					if !ok {
+						continue
+					}
+				}
+
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if len(next) == 0 {
+				return
+			}
+			cursor = next
+		}
+	}
+}
+
+// fromSlice yields items of a slice, until ctx is done.
+func fromSlice[T any](ctx context.Context, items []T) iter.Seq2[T, error] {
+	return chunks(ctx, func(context.Context, string) ([]T, string, error) {
+		return items, "", nil
+	}, nil)
+}
+
+// ctxErr wraps an error of a done context.
+func ctxErr(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return secretsmanagererrors.Error{
+			Err:         secretsmanagererrors.ErrCannotDoRequest,
+			Desc:        "iteration is stopped: " + err.Error(),
+			Cause:       err,
+			ContextDone: true,
+		}
+	}
+
+	return nil
+}
+
+// CompareTimes compares times formatted in RFC 3339, unparsable ones are compared as strings.
+func CompareTimes(a, b string) int {
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+
+	return ta.Compare(tb)
+}
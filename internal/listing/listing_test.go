@@ -0,0 +1,90 @@
+package listing
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+// pagedFetch returns items in chunks of two, cursors are offsets.
+func pagedFetch(items []int, fetched *int) Fetch[int] {
+	return func(_ context.Context, cursor string) ([]int, string, error) {
+		*fetched++
+
+		offset, _ := strconv.Atoi(cursor)
+		end := min(offset+2, len(items))
+		if end == len(items) {
+			return items[offset:end], "", nil
+		}
+		return items[offset:end], strconv.Itoa(end), nil
+	}
+}
+
+func TestSeqFetchesLazily(t *testing.T) {
+	var fetched int
+	seq := Seq(context.Background(), pagedFetch([]int{1, 2, 3, 4, 5}, &fetched), Options[int]{
+		Keep: func(item int) (bool, error) { return item%2 == 1, nil },
+	})
+	require.Zero(t, fetched)
+
+	var got []int
+	for item, err := range seq {
+		require.NoError(t, err)
+		got = append(got, item)
+		if item == 3 {
+			break
+		}
+	}
+
+	require.Equal(t, []int{1, 3}, got)
+	require.Equal(t, 2, fetched)
+}
+
+func TestSeqSorted(t *testing.T) {
+	var fetched int
+	seq := Seq(context.Background(), pagedFetch([]int{3, 1, 5, 2, 4}, &fetched), Options[int]{
+		Compare: func(a, b int) int { return b - a },
+	})
+
+	var got []int
+	for item, err := range seq {
+		require.NoError(t, err)
+		got = append(got, item)
+	}
+
+	require.Equal(t, []int{5, 4, 3, 2, 1}, got)
+	require.Equal(t, 3, fetched)
+}
+
+func TestSeqStopsOnDoneContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		fetched int
+		got     []int
+		errs    []error
+	)
+	for item, err := range Seq(ctx, pagedFetch([]int{1, 2, 3}, &fetched), Options[int]{}) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		got = append(got, item)
+		cancel()
+	}
+
+	require.Equal(t, []int{1}, got)
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0], secretsmanagererrors.ErrCannotDoRequest)
+	require.ErrorIs(t, errs[0], context.Canceled)
+}
+
+func TestCompareTimes(t *testing.T) {
+	require.Equal(t, -1, CompareTimes("2024-01-01T00:00:00Z", "2024-01-01T03:00:00+02:00"))
+	require.Equal(t, 1, CompareTimes("b", "a"))
+}
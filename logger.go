@@ -0,0 +1,44 @@
+package secretsmanager
+
+import (
+	"log/slog"
+
+	"github.com/selectel/secretsmanager-go/internal/httpclient"
+)
+
+// LogLevels — levels, at which events of requests are logged, see DefaultLogLevels.
+type LogLevels struct {
+	// Start is a level of a message logged before the first attempt of an operation.
+	Start slog.Level
+
+	// Finish is a level of a message logged when an operation succeeds.
+	Finish slog.Level
+
+	// Retry is a level of a message logged before every retry of an operation.
+	Retry slog.Level
+
+	// Error is a level of a message logged when an operation fails.
+	Error slog.Level
+}
+
+// DefaultLogLevels returns LogLevels, that log start and finish of operations at Debug,
+// retries at Warn and failures at Error level.
+func DefaultLogLevels() LogLevels {
+	return LogLevels(httpclient.DefaultLogLevels())
+}
+
+// WithLogger is a functional parameter for SecretsManagerClient, used to log requests.
+// Only an operation, a method, a URL, a status, a duration and an error are logged:
+// neither X-Auth-Token nor bodies of requests and responses with secret values ever get to the logger.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *Client) {
+		c.cfg.logger = logger
+	}
+}
+
+// WithLogLevels is a functional parameter for SecretsManagerClient, used to change levels of logged events.
+func WithLogLevels(levels LogLevels) ClientOption {
+	return func(c *Client) {
+		c.cfg.logLevels = httpclient.LogLevels(levels)
+	}
+}
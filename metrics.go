@@ -0,0 +1,25 @@
+package secretsmanager
+
+import "time"
+
+// MetricsCollector receives events of requests made by Secrets and Certificates services,
+// see promsecretsmanager package for a Prometheus implementation.
+// Implementations have to be safe for concurrent use.
+type MetricsCollector interface {
+	// ObserveRequestStart is called before the first attempt of an operation.
+	ObserveRequestStart(operation string)
+
+	// ObserveRetry is called before every retry of an operation.
+	ObserveRetry(operation string)
+
+	// ObserveRequest is called when an operation is finished, statusCode is 0
+	// if no response has been received, err is nil on success.
+	ObserveRequest(operation string, statusCode int, err error, duration time.Duration)
+}
+
+// WithMetrics is a functional parameter for SecretsManagerClient, used to collect metrics of requests.
+func WithMetrics(collector MetricsCollector) ClientOption {
+	return func(c *Client) {
+		c.cfg.metricsCollector = collector
+	}
+}
@@ -0,0 +1,56 @@
+// Package middleware provides types to intercept requests made by the Secrets Manager SDK.
+package middleware
+
+import (
+	"context"
+	"net/http"
+)
+
+// Request — a logical request to Secrets Manager API, passed through middlewares.
+type Request struct {
+	// Operation is a name of an SDK operation, like "secrets.Get" or "certs.AddConsumers".
+	Operation string
+
+	// Resource is a secret key or a certificate ID the operation is performed on,
+	// it is empty for operations like List or certs.Create.
+	Resource string
+
+	Method string
+	URL    string
+
+	// Header contains additional headers, that are set on the HTTP request.
+	Header http.Header
+	Body   []byte
+}
+
+// Response — a response received from Secrets Manager API.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Handler — performs a Request, the innermost Handler sends it to the backend.
+// If the backend responded with an error status, both Response and error are returned.
+type Handler func(ctx context.Context, req *Request) (*Response, error)
+
+// Middleware — wraps a Handler, it can modify a request, observe a response or
+// short-circuit the call by returning without calling next.
+type Middleware func(next Handler) Handler
+
+// Operation — an SDK operation, that makes several requests, like "secrets.Put" or "secrets.GetMany".
+type Operation struct {
+	Name string
+
+	// Resource is a secret key the operation is performed on,
+	// it is empty for operations on many secrets.
+	Resource string
+}
+
+// OperationObserver — is notified about operations, that make several requests, so requests
+// passed to middlewares can be grouped by them, for example into child spans of a span of an operation.
+type OperationObserver interface {
+	// StartOperation is called before an operation, the returned context is passed to its requests
+	// and end is called with the result of the operation.
+	StartOperation(ctx context.Context, op Operation) (_ context.Context, end func(err error))
+}
@@ -0,0 +1,191 @@
+// Package otelsecretsmanager provides OpenTelemetry tracing for the Secrets Manager SDK.
+//
+// Register it as a middleware of a client, and as an observer of operations, that make several requests,
+// so their requests are grouped under a span of the operation:
+//
+//	cl, err := secretsmanager.New(
+//		secretsmanager.WithAuthOpts(authOpts),
+//		secretsmanager.WithMiddleware(otelsecretsmanager.Middleware()),
+//		secretsmanager.WithOperationObserver(otelsecretsmanager.OperationObserver()),
+//	)
+package otelsecretsmanager
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/selectel/secretsmanager-go/middleware"
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+// instrumentationName is a name of the tracer, that creates spans.
+const instrumentationName = "github.com/selectel/secretsmanager-go/otelsecretsmanager"
+
+// redactedResource replaces secret keys and certificate IDs, when redaction is enabled.
+const redactedResource = "[REDACTED]"
+
+// Attributes set on spans.
+const (
+	AttributeOperation      = attribute.Key("secretsmanager.operation")
+	AttributeSecretKey      = attribute.Key("secretsmanager.secret.key")
+	AttributeCertificateID  = attribute.Key("secretsmanager.certificate.id")
+	AttributeErrorCode      = attribute.Key("secretsmanager.error.code")
+	AttributeHTTPMethod     = attribute.Key("http.request.method")
+	AttributeHTTPStatusCode = attribute.Key("http.response.status_code")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	propagators    propagation.TextMapPropagator
+	redact         func(operation, resource string) string
+}
+
+// Option configures the tracing middleware.
+type Option func(*config)
+
+// WithTracerProvider sets a TracerProvider, the global one is used by default.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithPropagators sets propagators used to pass trace context to the backend,
+// the global ones are used by default.
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagators = p
+	}
+}
+
+// WithRedactedResources replaces secret keys and certificate IDs in span attributes with [REDACTED].
+func WithRedactedResources() Option {
+	return WithResourceRedactor(func(_, _ string) string {
+		return redactedResource
+	})
+}
+
+// WithResourceRedactor sets a function, that returns a value of a secret key or a certificate ID
+// recorded in span attributes, for example a hash or a prefix of a key.
+func WithResourceRedactor(redact func(operation, resource string) string) Option {
+	return func(c *config) {
+		c.redact = redact
+	}
+}
+
+func newConfig(opts []Option) config {
+	cfg := config{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.tracerProvider == nil {
+		cfg.tracerProvider = otel.GetTracerProvider()
+	}
+	if cfg.propagators == nil {
+		cfg.propagators = otel.GetTextMapPropagator()
+	}
+
+	return cfg
+}
+
+// Middleware returns a middleware, that creates a client span per request of an SDK operation
+// and propagates trace context to the backend.
+func Middleware(opts ...Option) middleware.Middleware {
+	cfg := newConfig(opts)
+	tracer := cfg.tracerProvider.Tracer(instrumentationName)
+
+	return func(next middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req *middleware.Request) (*middleware.Response, error) {
+			ctx, span := tracer.Start(ctx, req.Operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(cfg.requestAttributes(req)...),
+			)
+			defer span.End()
+
+			cfg.propagators.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			resp, err := next(ctx, req)
+			if resp != nil {
+				span.SetAttributes(AttributeHTTPStatusCode.Int(resp.StatusCode))
+			}
+
+			if err != nil {
+				recordError(span, err)
+			}
+
+			return resp, err
+		}
+	}
+}
+
+// OperationObserver returns an observer, that creates an internal span per SDK operation, that makes
+// several requests (like secrets.Put or secrets.GetMany), spans of its requests become its children.
+func OperationObserver(opts ...Option) middleware.OperationObserver {
+	cfg := newConfig(opts)
+
+	return operationObserver{
+		cfg:    cfg,
+		tracer: cfg.tracerProvider.Tracer(instrumentationName),
+	}
+}
+
+type operationObserver struct {
+	cfg    config
+	tracer trace.Tracer
+}
+
+func (o operationObserver) StartOperation(
+	ctx context.Context, op middleware.Operation,
+) (context.Context, func(err error)) {
+	ctx, span := o.tracer.Start(ctx, op.Name,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(o.cfg.resourceAttributes(op.Name, op.Resource)...),
+	)
+
+	return ctx, func(err error) {
+		if err != nil {
+			recordError(span, err)
+		}
+		span.End()
+	}
+}
+
+func recordError(span trace.Span, err error) {
+	code := secretsmanagererrors.Code(err)
+	if len(code) > 0 {
+		span.SetAttributes(AttributeErrorCode.String(code))
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, code)
+}
+
+func (cfg config) requestAttributes(req *middleware.Request) []attribute.KeyValue {
+	return append(cfg.resourceAttributes(req.Operation, req.Resource), AttributeHTTPMethod.String(req.Method))
+}
+
+func (cfg config) resourceAttributes(operation, resource string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		AttributeOperation.String(operation),
+	}
+
+	if len(resource) == 0 {
+		return attrs
+	}
+
+	if cfg.redact != nil {
+		resource = cfg.redact(operation, resource)
+	}
+
+	if strings.HasPrefix(operation, "certs.") {
+		return append(attrs, AttributeCertificateID.String(resource))
+	}
+
+	return append(attrs, AttributeSecretKey.String(resource))
+}
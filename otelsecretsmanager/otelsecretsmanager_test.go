@@ -0,0 +1,130 @@
+package otelsecretsmanager_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/selectel/secretsmanager-go"
+	"github.com/selectel/secretsmanager-go/otelsecretsmanager"
+	"github.com/selectel/secretsmanager-go/service/secrets"
+)
+
+const testDummyEndpoint = "http://example.com/"
+
+func newTracedClient(
+	t *testing.T, opts ...otelsecretsmanager.Option,
+) (*secretsmanager.Client, *tracetest.SpanRecorder) {
+	t.Helper()
+
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	gock.InterceptClient(httpClient)
+
+	recorder := tracetest.NewSpanRecorder()
+	opts = append(opts,
+		otelsecretsmanager.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+		otelsecretsmanager.WithPropagators(propagation.TraceContext{}),
+	)
+
+	cl, err := secretsmanager.New(
+		secretsmanager.WithAuthOpts(&secretsmanager.AuthOpts{KeystoneToken: "dummy"}),
+		secretsmanager.WithCustomURLSecrets(testDummyEndpoint),
+		secretsmanager.WithCustomURLCertificates(testDummyEndpoint),
+		secretsmanager.WithCustomHTTPClient(httpClient),
+		secretsmanager.WithMiddleware(otelsecretsmanager.Middleware(opts...)),
+		secretsmanager.WithOperationObserver(otelsecretsmanager.OperationObserver(opts...)),
+	)
+	require.NoError(t, err)
+
+	return cl, recorder
+}
+
+func TestSpanPerOperation(t *testing.T) {
+	defer gock.Off()
+	cl, recorder := newTracedClient(t)
+
+	gock.New(testDummyEndpoint).
+		Get("/v1/dummy-secret").
+		HeaderPresent("Traceparent").
+		Reply(http.StatusNotFound).
+		JSON(map[string]string{"status_text": "NOT_FOUND", "error_text": "secret not found"})
+
+	_, err := cl.Secrets.Get(context.Background(), "dummy-secret")
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "secrets.Get", spans[0].Name())
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+	require.Subset(t, spans[0].Attributes(), []attribute.KeyValue{
+		otelsecretsmanager.AttributeOperation.String("secrets.Get"),
+		otelsecretsmanager.AttributeSecretKey.String("dummy-secret"),
+		otelsecretsmanager.AttributeHTTPStatusCode.Int(http.StatusNotFound),
+		otelsecretsmanager.AttributeErrorCode.String("NOT_FOUND"),
+	})
+	require.True(t, gock.IsDone())
+}
+
+func TestRedactedResources(t *testing.T) {
+	defer gock.Off()
+	cl, recorder := newTracedClient(t, otelsecretsmanager.WithRedactedResources())
+
+	gock.New(testDummyEndpoint).
+		Delete("/v1/cert/dummy-cert").
+		Reply(http.StatusNoContent)
+
+	require.NoError(t, cl.Certificates.Delete(context.Background(), "dummy-cert"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+	require.Subset(t, spans[0].Attributes(), []attribute.KeyValue{
+		otelsecretsmanager.AttributeCertificateID.String("[REDACTED]"),
+		otelsecretsmanager.AttributeHTTPStatusCode.Int(http.StatusNoContent),
+	})
+	require.True(t, gock.IsDone())
+}
+
+func TestSpanPerCompositeOperation(t *testing.T) {
+	defer gock.Off()
+	cl, recorder := newTracedClient(t)
+
+	gock.New(testDummyEndpoint).
+		Post("/v1/dummy-secret").
+		Reply(http.StatusConflict).
+		JSON(map[string]string{"status_text": "CONFLICT"})
+	gock.New(testDummyEndpoint).
+		Put("/v1/dummy-secret").
+		Reply(http.StatusNoContent)
+
+	_, err := cl.Secrets.Put(context.Background(), secrets.UserSecret{Key: "dummy-secret", Value: []byte("value")})
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	// Spans of requests end before the span of the operation.
+	put := spans[2]
+	require.Equal(t, "secrets.Put", put.Name())
+	require.Equal(t, trace.SpanKindInternal, put.SpanKind())
+	require.Equal(t, codes.Unset, put.Status().Code)
+	require.Subset(t, put.Attributes(), []attribute.KeyValue{
+		otelsecretsmanager.AttributeSecretKey.String("dummy-secret"),
+	})
+
+	for i, name := range []string{"secrets.Create", "secrets.Update"} {
+		require.Equal(t, name, spans[i].Name())
+		require.Equal(t, put.SpanContext().SpanID(), spans[i].Parent().SpanID())
+	}
+	require.True(t, gock.IsDone())
+}
@@ -0,0 +1,158 @@
+// Package promsecretsmanager provides Prometheus metrics for the Secrets Manager SDK.
+//
+// Register a collector on your registry and pass it to a client:
+//
+//	collector := promsecretsmanager.NewCollector()
+//	registry.MustRegister(collector)
+//
+//	cl, err := secretsmanager.New(
+//		secretsmanager.WithAuthOpts(authOpts),
+//		secretsmanager.WithMetrics(collector),
+//	)
+package promsecretsmanager
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+// defaultNamespace is a prefix of all metric names.
+const defaultNamespace = "secretsmanager"
+
+// Labels of metrics.
+const (
+	LabelOperation = "operation"
+	LabelStatus    = "status"
+	LabelErrorCode = "error_code"
+)
+
+type config struct {
+	namespace   string
+	constLabels prometheus.Labels
+	buckets     []float64
+}
+
+// Option configures the Collector.
+type Option func(*config)
+
+// WithNamespace sets a prefix of metric names, "secretsmanager" by default.
+func WithNamespace(namespace string) Option {
+	return func(c *config) {
+		c.namespace = namespace
+	}
+}
+
+// WithConstLabels sets labels added to all metrics, for example to tell clients apart.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return func(c *config) {
+		c.constLabels = labels
+	}
+}
+
+// WithBuckets sets buckets of the latency histogram, prometheus.DefBuckets by default.
+func WithBuckets(buckets []float64) Option {
+	return func(c *config) {
+		c.buckets = buckets
+	}
+}
+
+// Collector collects metrics of requests made by the SDK.
+// It implements both prometheus.Collector and secretsmanager.MetricsCollector.
+type Collector struct {
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	retries  *prometheus.CounterVec
+	inFlight *prometheus.GaugeVec
+	duration *prometheus.HistogramVec
+}
+
+// NewCollector returns a Collector, that has to be registered on a prometheus.Registerer.
+func NewCollector(opts ...Option) *Collector {
+	cfg := config{
+		namespace: defaultNamespace,
+		buckets:   prometheus.DefBuckets,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   cfg.namespace,
+			Name:        "requests_total",
+			Help:        "Number of finished operations.",
+			ConstLabels: cfg.constLabels,
+		}, []string{LabelOperation, LabelStatus, LabelErrorCode}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   cfg.namespace,
+			Name:        "request_errors_total",
+			Help:        "Number of failed operations by error code.",
+			ConstLabels: cfg.constLabels,
+		}, []string{LabelOperation, LabelErrorCode}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   cfg.namespace,
+			Name:        "request_retries_total",
+			Help:        "Number of retried attempts.",
+			ConstLabels: cfg.constLabels,
+		}, []string{LabelOperation}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   cfg.namespace,
+			Name:        "requests_in_flight",
+			Help:        "Number of operations in progress.",
+			ConstLabels: cfg.constLabels,
+		}, []string{LabelOperation}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   cfg.namespace,
+			Name:        "request_duration_seconds",
+			Help:        "Duration of operations including retries.",
+			ConstLabels: cfg.constLabels,
+			Buckets:     cfg.buckets,
+		}, []string{LabelOperation, LabelStatus}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.errors.Describe(ch)
+	c.retries.Describe(ch)
+	c.inFlight.Describe(ch)
+	c.duration.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.errors.Collect(ch)
+	c.retries.Collect(ch)
+	c.inFlight.Collect(ch)
+	c.duration.Collect(ch)
+}
+
+// ObserveRequestStart implements secretsmanager.MetricsCollector.
+func (c *Collector) ObserveRequestStart(operation string) {
+	c.inFlight.WithLabelValues(operation).Inc()
+}
+
+// ObserveRetry implements secretsmanager.MetricsCollector.
+func (c *Collector) ObserveRetry(operation string) {
+	c.retries.WithLabelValues(operation).Inc()
+}
+
+// ObserveRequest implements secretsmanager.MetricsCollector.
+func (c *Collector) ObserveRequest(operation string, statusCode int, err error, duration time.Duration) {
+	status := strconv.Itoa(statusCode)
+	code := secretsmanagererrors.Code(err)
+
+	c.inFlight.WithLabelValues(operation).Dec()
+	c.requests.WithLabelValues(operation, status, code).Inc()
+	c.duration.WithLabelValues(operation, status).Observe(duration.Seconds())
+
+	if err != nil {
+		c.errors.WithLabelValues(operation, code).Inc()
+	}
+}
@@ -0,0 +1,80 @@
+package promsecretsmanager_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/selectel/secretsmanager-go"
+	"github.com/selectel/secretsmanager-go/promsecretsmanager"
+)
+
+const testDummyEndpoint = "http://example.com/"
+
+var _ secretsmanager.MetricsCollector = (*promsecretsmanager.Collector)(nil)
+
+func TestCollector(t *testing.T) {
+	defer gock.Off()
+
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	gock.InterceptClient(httpClient)
+
+	collector := promsecretsmanager.NewCollector()
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(collector))
+
+	cl, err := secretsmanager.New(
+		secretsmanager.WithAuthOpts(&secretsmanager.AuthOpts{KeystoneToken: "dummy"}),
+		secretsmanager.WithCustomURLSecrets(testDummyEndpoint),
+		secretsmanager.WithCustomHTTPClient(httpClient),
+		secretsmanager.WithRetryPolicy(secretsmanager.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+		secretsmanager.WithMetrics(collector),
+	)
+	require.NoError(t, err)
+
+	gock.New(testDummyEndpoint).
+		Get("/v1/dummy-secret").
+		Times(2).
+		Reply(http.StatusTooManyRequests).
+		JSON(map[string]string{"status_text": "TOO_MANY_REQUESTS"})
+
+	gock.New(testDummyEndpoint).
+		Delete("/v1/dummy-secret").
+		Reply(http.StatusNoContent)
+
+	ctx := context.Background()
+	_, err = cl.Secrets.Get(ctx, "dummy-secret")
+	require.Error(t, err)
+	require.NoError(t, cl.Secrets.Delete(ctx, "dummy-secret"))
+
+	expected := `
+# HELP secretsmanager_requests_total Number of finished operations.
+# TYPE secretsmanager_requests_total counter
+secretsmanager_requests_total{error_code="",operation="secrets.Delete",status="204"} 1
+secretsmanager_requests_total{error_code="TOO_MANY_REQUESTS",operation="secrets.Get",status="429"} 1
+# HELP secretsmanager_request_errors_total Number of failed operations by error code.
+# TYPE secretsmanager_request_errors_total counter
+secretsmanager_request_errors_total{error_code="TOO_MANY_REQUESTS",operation="secrets.Get"} 1
+# HELP secretsmanager_request_retries_total Number of retried attempts.
+# TYPE secretsmanager_request_retries_total counter
+secretsmanager_request_retries_total{operation="secrets.Get"} 1
+# HELP secretsmanager_requests_in_flight Number of operations in progress.
+# TYPE secretsmanager_requests_in_flight gauge
+secretsmanager_requests_in_flight{operation="secrets.Delete"} 0
+secretsmanager_requests_in_flight{operation="secrets.Get"} 0
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"secretsmanager_requests_total",
+		"secretsmanager_request_errors_total",
+		"secretsmanager_request_retries_total",
+		"secretsmanager_requests_in_flight",
+	))
+	require.Equal(t, 2, testutil.CollectAndCount(collector, "secretsmanager_request_duration_seconds"))
+}
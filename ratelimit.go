@@ -0,0 +1,41 @@
+package secretsmanager
+
+import (
+	"github.com/selectel/secretsmanager-go/internal/httpclient"
+)
+
+// RateLimit is a client-side token bucket limit shared by Secrets and Certificates services.
+// Requests wait for a free token respecting ctx cancellation.
+// When the backend returns 429, the rate is reduced and restored gradually with successful requests.
+type RateLimit struct {
+	// RequestsPerSecond is a sustained rate of requests, zero means no client-wide limit.
+	RequestsPerSecond float64
+
+	// Burst is a maximum number of requests, that can be made at once.
+	Burst int
+
+	// PerMethod sets additional budgets for HTTP methods (like http.MethodPost),
+	// a request has to fit into both client-wide and its method budgets.
+	PerMethod map[string]MethodRateLimit
+}
+
+// MethodRateLimit is a budget of requests with a specific HTTP method.
+type MethodRateLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// WithRateLimit is a functional parameter for SecretsManagerClient, used to limit a rate of requests.
+func WithRateLimit(limit RateLimit) ClientOption {
+	return func(c *Client) {
+		perMethod := make(map[string]httpclient.RateLimit, len(limit.PerMethod))
+		for method, ml := range limit.PerMethod {
+			perMethod[method] = httpclient.RateLimit(ml)
+		}
+
+		c.cfg.rateLimiter = httpclient.NewRateLimiter(
+			httpclient.RateLimit{RequestsPerSecond: limit.RequestsPerSecond, Burst: limit.Burst},
+			perMethod,
+		)
+	}
+}
@@ -0,0 +1,63 @@
+package secretsmanager
+
+import (
+	"context"
+	"time"
+
+	"github.com/selectel/secretsmanager-go/internal/httpclient"
+)
+
+const (
+	// defaultRetryMaxAttempts represents the default number of attempts including the first one.
+	defaultRetryMaxAttempts = 3
+
+	// defaultRetryInitialBackoff represents the default delay before the first retry.
+	defaultRetryInitialBackoff = 200 * time.Millisecond
+
+	// defaultRetryMaxBackoff represents the default maximum delay between retries.
+	defaultRetryMaxBackoff = 5 * time.Second
+)
+
+// RetryPolicy defines how failed requests are retried.
+// Requests are retried on network errors and on 429, 502, 503 and 504 statuses
+// with exponential backoff and jitter, Retry-After header returned by the backend is honoured.
+// Non-idempotent requests (POST) are retried only if they were rejected with 429,
+// carry an idempotency key (see WithIdempotencyKey) or RetryNonIdempotent is set.
+type RetryPolicy struct {
+	// MaxAttempts is a maximum number of attempts including the first one,
+	// values less than 2 disable retries.
+	MaxAttempts int
+
+	// InitialBackoff is a delay before the first retry, it is doubled for each next retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff limits a delay between retries, zero means no limit.
+	// If Retry-After returned by the backend asks to wait longer, the request is not retried.
+	MaxBackoff time.Duration
+
+	// RetryNonIdempotent allows to retry non-idempotent requests without an idempotency key.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns a RetryPolicy with 3 attempts and backoff from 200ms up to 5s.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    defaultRetryMaxAttempts,
+		InitialBackoff: defaultRetryInitialBackoff,
+		MaxBackoff:     defaultRetryMaxBackoff,
+	}
+}
+
+// WithRetryPolicy is a functional parameter for SecretsManagerClient, used to retry failed requests.
+// By default, every request is made exactly once.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.cfg.retryPolicy = httpclient.RetryPolicy(policy)
+	}
+}
+
+// WithIdempotencyKey returns a context, requests made with which carry the given
+// key in Idempotency-Key header, so non-idempotent ones can be retried safely.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return httpclient.WithIdempotencyKey(ctx, key)
+}
@@ -1,10 +1,16 @@
 package secretsmanager
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/selectel/secretsmanager-go/internal/auth"
 	"github.com/selectel/secretsmanager-go/internal/httpclient"
+	"github.com/selectel/secretsmanager-go/middleware"
 	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
 	"github.com/selectel/secretsmanager-go/service/certs"
 	"github.com/selectel/secretsmanager-go/service/secrets"
@@ -16,6 +22,23 @@ const (
 
 	// URL for working with certificates.
 	defaultAPIURLUserCertificates = "https://cloud.api.selcloud.ru/certificate-manager/"
+
+	// URL of Keystone, used to issue tokens.
+	defaultAuthURL = "https://cloud.api.selcloud.ru/identity/v3/"
+
+	// Interface of endpoints, that are discovered from the service catalog.
+	defaultEndpointInterface = "public"
+
+	// Timeout of a request to Keystone, that is made by New to discover endpoints.
+	endpointDiscoveryTimeout = 30 * time.Second
+)
+
+const (
+	// Type of the Secrets Manager service in the Keystone service catalog.
+	serviceTypeSecrets = "secrets-manager"
+
+	// Type of the Certificate Manager service in the Keystone service catalog.
+	serviceTypeUserCertificates = "certificate-manager"
 )
 
 // Client — implements operations to work with the Secrets Manager API using the Keystone Token.
@@ -29,10 +52,37 @@ type ClientOption func(*Client)
 
 // AuthOpts is a helper structure used during client initialization.
 // Depending on the data passed in the structure
-// (like Keystone Token or newer that will be added in future)
+// (like Keystone Token, Username and Password or Application Credential)
 // the required authentication structure will be selected.
+// Only one of the authentication methods can be set at once.
 type AuthOpts struct {
+	// KeystoneToken is a pre-issued token, it won't be refreshed by the SDK.
 	KeystoneToken string
+
+	// AuthURL is a Keystone endpoint used to issue tokens,
+	// defaults to Selectel Keystone if empty.
+	AuthURL string
+
+	// Username, Password and DomainName are exchanged for a Keystone token,
+	// that is refreshed automatically before it expires.
+	Username   string
+	Password   string
+	DomainName string
+
+	// AccountID is a Selectel account ID, set it to authenticate as a Selectel service user.
+	// It is used as DomainName and ProjectDomainName, if they are empty.
+	AccountID string
+
+	// ApplicationCredentialID and ApplicationCredentialSecret are exchanged for a Keystone token,
+	// that is refreshed automatically before it expires.
+	// Application credential is already bound to a project, so project fields must be empty.
+	ApplicationCredentialID     string
+	ApplicationCredentialSecret string
+
+	// ProjectID or ProjectName with ProjectDomainName define a scope of an issued token.
+	ProjectID         string
+	ProjectName       string
+	ProjectDomainName string
 }
 
 // WithAuthOpts is a functional parameter for SecretsManagerClient, used to set on of implementations of AuthType.
@@ -42,6 +92,48 @@ func WithAuthOpts(authOpts *AuthOpts) ClientOption {
 	}
 }
 
+// WithTokenProvider is a functional parameter for SecretsManagerClient, used to set a custom TokenProvider.
+// It can't be combined with WithAuthOpts.
+func WithTokenProvider(provider TokenProvider) ClientOption {
+	return func(c *Client) {
+		c.cfg.tokenProvider = provider
+	}
+}
+
+// WithRegion is a functional parameter for SecretsManagerClient, used to set a region.
+// Endpoints of services in this region are discovered from the service catalog, unless custom URLs are set.
+// The catalog is received only when the client issues Keystone tokens by itself, so New fails
+// with ErrClientBadConfig, if a region is set along with KeystoneToken or a TokenProvider.
+func WithRegion(region string) ClientOption {
+	return func(c *Client) {
+		c.cfg.Region = region
+	}
+}
+
+// WithEndpointInterface is a functional parameter for SecretsManagerClient,
+// used to set an interface (public, internal or admin) of endpoints discovered from the service catalog.
+func WithEndpointInterface(iface string) ClientOption {
+	return func(c *Client) {
+		c.cfg.EndpointInterface = iface
+	}
+}
+
+// WithMiddleware is a functional parameter for SecretsManagerClient, used to register middlewares,
+// that wrap every request of Secrets and Certificates services. The first middleware is the outermost.
+func WithMiddleware(middlewares ...middleware.Middleware) ClientOption {
+	return func(c *Client) {
+		c.cfg.middlewares = append(c.cfg.middlewares, middlewares...)
+	}
+}
+
+// WithOperationObserver is a functional parameter for SecretsManagerClient, used to register observers
+// of operations, that make several requests, like Secrets.Put or Secrets.GetMany.
+func WithOperationObserver(observers ...middleware.OperationObserver) ClientOption {
+	return func(c *Client) {
+		c.cfg.operationObservers = append(c.cfg.operationObservers, observers...)
+	}
+}
+
 func WithCustomURLSecrets(url string) ClientOption {
 	return func(c *Client) {
 		c.cfg.APIURLSecrets = url
@@ -64,43 +156,153 @@ type config struct {
 	APIURLSecrets          string
 	APIURLUserCertificates string
 
+	// Region and EndpointInterface are used to discover URLs from the Keystone service catalog.
+	Region            string
+	EndpointInterface string
+
 	// AuthOpts contains data to authenticate against Selectel Secrets Manager API.
-	authOpts         *AuthOpts
-	customHTTPClient *http.Client
+	authOpts      *AuthOpts
+	tokenProvider TokenProvider
+	// envAuthOpts builds AuthOpts from the environment, it is used only if none of them are set by options.
+	envAuthOpts        func() (*AuthOpts, error)
+	customHTTPClient   *http.Client
+	retryPolicy        httpclient.RetryPolicy
+	rateLimiter        *httpclient.RateLimiter
+	middlewares        []middleware.Middleware
+	operationObservers []middleware.OperationObserver
+	metricsCollector   MetricsCollector
+	logger             *slog.Logger
+	logLevels          httpclient.LogLevels
+	cacheOpts          *CacheOptions
 }
 
 func defaultConfig() *config {
 	return &config{
 		APIURLSecrets:          defaultAPIURLSecrets,
 		APIURLUserCertificates: defaultAPIURLUserCertificates,
+		EndpointInterface:      defaultEndpointInterface,
+		logLevels:              httpclient.DefaultLogLevels(),
 	}
 }
 
 func New(options ...ClientOption) (*Client, error) {
+	return newClient(defaultConfig(), options...)
+}
+
+// newClient — initializes a client from a base config with options applied on top of it.
+func newClient(cfg *config, options ...ClientOption) (*Client, error) {
 	cl := &Client{
-		cfg: defaultConfig(),
+		cfg: cfg,
 	}
 
 	for _, option := range options {
 		option(cl)
 	}
 
-	auth, err := newAuth(cl.cfg.authOpts)
+	auth, err := cl.cfg.newAuth()
+	if err != nil {
+		return nil, err
+	}
+
+	err = cl.cfg.discoverEndpoints(auth)
 	if err != nil {
 		return nil, err
 	}
 
 	httpClient := httpclient.New(auth, cl.cfg.customHTTPClient)
+	httpClient.RetryPolicy = cl.cfg.retryPolicy
+	httpClient.RateLimiter = cl.cfg.rateLimiter
+	httpClient.Middlewares = cl.cfg.middlewares
+	httpClient.OperationObservers = cl.cfg.operationObservers
+	httpClient.Observer = cl.cfg.metricsCollector
+	httpClient.Logger = cl.cfg.logger
+	httpClient.LogLevels = cl.cfg.logLevels
 
-	cl.Secrets = secrets.New(cl.cfg.APIURLSecrets, httpClient)
-	cl.Certificates = certs.New(cl.cfg.APIURLUserCertificates, httpClient)
+	secretsOpts, certsOpts := cl.cfg.serviceOptions()
+	cl.Secrets = secrets.New(cl.cfg.APIURLSecrets, httpClient, secretsOpts...)
+	cl.Certificates = certs.New(cl.cfg.APIURLUserCertificates, httpClient, certsOpts...)
 
 	return cl, nil
 }
 
+// discoverEndpoints — replaces default URLs with the ones from the Keystone service catalog,
+// if a region is set. It issues a token during client initialization, so the auth type has to receive a catalog.
+func (cfg *config) discoverEndpoints(authType auth.Type) error {
+	if len(cfg.Region) == 0 ||
+		(cfg.APIURLSecrets != defaultAPIURLSecrets && cfg.APIURLUserCertificates != defaultAPIURLUserCertificates) {
+		return nil
+	}
+
+	cp, ok := authType.(auth.CatalogProvider)
+	if !ok {
+		return secretsmanagererrors.Error{
+			Err: secretsmanagererrors.ErrClientBadConfig,
+			Desc: fmt.Sprintf("region %q is set, but endpoints can't be discovered without a service catalog, "+
+				"it is received only when the SDK issues tokens by itself; set custom URLs instead", cfg.Region),
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), endpointDiscoveryTimeout)
+	defer cancel()
+
+	if cfg.APIURLSecrets == defaultAPIURLSecrets {
+		url, err := cp.EndpointURL(ctx, serviceTypeSecrets, cfg.Region, cfg.EndpointInterface)
+		if err != nil {
+			return err //nolint:wrapcheck // EndpointURL already wraps the error.
+		}
+		cfg.APIURLSecrets = url
+	}
+
+	if cfg.APIURLUserCertificates == defaultAPIURLUserCertificates {
+		url, err := cp.EndpointURL(ctx, serviceTypeUserCertificates, cfg.Region, cfg.EndpointInterface)
+		if err != nil {
+			return err //nolint:wrapcheck // EndpointURL already wraps the error.
+		}
+		cfg.APIURLUserCertificates = url
+	}
+
+	return nil
+}
+
+// newAuth — chooses between a TokenProvider set by user and the one built from AuthOpts.
+func (cfg *config) newAuth() (auth.Type, error) {
+	err := cfg.resolveEnvAuthOpts()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.tokenProvider == nil {
+		return newAuth(cfg.authOpts, cfg.customHTTPClient)
+	}
+
+	if cfg.authOpts != nil {
+		return nil, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrClientNoAuthOpts,
+			Desc: "both AuthOpts and TokenProvider are provided, only one of them can be used",
+		}
+	}
+
+	return cfg.tokenProvider, nil
+}
+
+// resolveEnvAuthOpts — sets AuthOpts from the environment, if neither AuthOpts nor a TokenProvider is set.
+func (cfg *config) resolveEnvAuthOpts() error {
+	if cfg.envAuthOpts == nil || cfg.authOpts != nil || cfg.tokenProvider != nil {
+		return nil
+	}
+
+	authOpts, err := cfg.envAuthOpts()
+	if err != nil {
+		return err
+	}
+	cfg.authOpts = authOpts
+
+	return nil
+}
+
 // newAuth — is a helper func, that checks if any of AuthOpts are passed into client
 // and depending on given smcl.authOpts, decide which independent supported auth.Type to set.
-func newAuth(authOpts *AuthOpts) (auth.Type, error) {
+func newAuth(authOpts *AuthOpts, httpClient *http.Client) (auth.Type, error) {
 	if authOpts == nil {
 		return nil, secretsmanagererrors.Error{
 			Err:  secretsmanagererrors.ErrClientNoAuthOpts,
@@ -108,8 +310,31 @@ func newAuth(authOpts *AuthOpts) (auth.Type, error) {
 		}
 	}
 
-	var authType auth.Type
-	if len(authOpts.KeystoneToken) > 0 {
+	var methods []string
+	if authOpts.hasKeystoneToken() {
+		methods = append(methods, "KeystoneToken")
+	}
+	if authOpts.hasPassword() {
+		methods = append(methods, "Username and Password")
+	}
+	if authOpts.hasApplicationCredential() {
+		methods = append(methods, "ApplicationCredentialID and ApplicationCredentialSecret")
+	}
+
+	if len(methods) > 1 {
+		return nil, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrClientNoAuthOpts,
+			Desc: "provided AuthOpts are ambiguous, only one of them can be set: " + strings.Join(methods, ", "),
+		}
+	}
+
+	authURL := authOpts.AuthURL
+	if len(authURL) == 0 {
+		authURL = defaultAuthURL
+	}
+
+	switch {
+	case authOpts.hasKeystoneToken():
 		ksta, err := auth.NewKeystoneTokenAuth(authOpts.KeystoneToken)
 		if err != nil {
 			return nil, secretsmanagererrors.Error{
@@ -117,8 +342,77 @@ func newAuth(authOpts *AuthOpts) (auth.Type, error) {
 				Desc: err.Error(),
 			}
 		}
-		authType = ksta
+		return ksta, nil
+
+	case authOpts.hasPassword():
+		return newPasswordAuth(authURL, authOpts, httpClient)
+
+	case authOpts.hasApplicationCredential():
+		if len(authOpts.ProjectID) > 0 || len(authOpts.ProjectName) > 0 {
+			return nil, secretsmanagererrors.Error{
+				Err:  secretsmanagererrors.ErrClientNoAuthOpts,
+				Desc: "application credential is already bound to a project, ProjectID and ProjectName must be empty",
+			}
+		}
+
+		return auth.NewKeystoneApplicationCredentialAuth( //nolint:wrapcheck // Constructor already wraps the error.
+			authURL,
+			authOpts.ApplicationCredentialID,
+			authOpts.ApplicationCredentialSecret,
+			httpClient,
+		)
 	}
 
-	return authType, nil
+	return nil, secretsmanagererrors.Error{
+		Err: secretsmanagererrors.ErrClientNoAuthOpts,
+		Desc: "provided AuthOpts contain neither KeystoneToken, nor Username and Password, " +
+			"nor ApplicationCredentialID and ApplicationCredentialSecret",
+	}
+}
+
+// newPasswordAuth — builds password auth either for a regular Keystone user
+// or for a Selectel service user, if AccountID is set.
+func newPasswordAuth(authURL string, authOpts *AuthOpts, httpClient *http.Client) (auth.Type, error) {
+	domainName, projectDomainName := authOpts.DomainName, authOpts.ProjectDomainName
+
+	if len(authOpts.AccountID) > 0 {
+		if len(domainName) > 0 && domainName != authOpts.AccountID {
+			return nil, secretsmanagererrors.Error{
+				Err:  secretsmanagererrors.ErrClientNoAuthOpts,
+				Desc: "provided DomainName conflicts with AccountID of a service user",
+			}
+		}
+
+		domainName = authOpts.AccountID
+		if len(projectDomainName) == 0 {
+			projectDomainName = authOpts.AccountID
+		}
+	}
+
+	return auth.NewKeystonePasswordAuth( //nolint:wrapcheck // NewKeystonePasswordAuth already wraps the error.
+		authURL,
+		auth.PasswordCredentials{
+			Username:   authOpts.Username,
+			Password:   authOpts.Password,
+			DomainName: domainName,
+		},
+		auth.Scope{
+			ProjectID:         authOpts.ProjectID,
+			ProjectName:       authOpts.ProjectName,
+			ProjectDomainName: projectDomainName,
+		},
+		httpClient,
+	)
+}
+
+func (ao *AuthOpts) hasKeystoneToken() bool {
+	return len(ao.KeystoneToken) > 0
+}
+
+func (ao *AuthOpts) hasPassword() bool {
+	return len(ao.Username) > 0 || len(ao.Password) > 0
+}
+
+func (ao *AuthOpts) hasApplicationCredential() bool {
+	return len(ao.ApplicationCredentialID) > 0 || len(ao.ApplicationCredentialSecret) > 0
 }
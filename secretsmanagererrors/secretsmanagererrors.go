@@ -3,6 +3,8 @@ package secretsmanagererrors
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 )
 
 const (
@@ -14,11 +16,18 @@ var (
 	// Errors for Auth.
 	ErrClientNoAuthOpts     = errors.New("CLIENT_NO_AUTH_METHOD")
 	ErrAuthTokenUnathorized = errors.New("AUTH_TOKEN_UNAUTHORIZED")
+	ErrCannotIssueAuthToken = errors.New("AUTH_CANNOT_ISSUE_TOKEN")
+
+	// Errors for Client configuration.
+	ErrClientBadConfig = errors.New("CLIENT_BAD_CONFIG")
 
 	// Errors for Secrets Service.
 	ErrEmptySecretName         = errors.New("EMPTY_SECRET_NAME")
+	ErrInvalidSecretName       = errors.New("INVALID_SECRET_NAME")
 	ErrEmptySecretValue        = errors.New("EMPTY_SECRET_DESC")
 	ErrCannotMarshalSecretBody = errors.New("CANNOT_MARSHAL_SECRET")
+	ErrInvalidSecretValue      = errors.New("INVALID_SECRET_VALUE")
+	ErrSecretOverwritten       = errors.New("SECRET_OVERWRITTEN")
 
 	// Errors for Certificates Service.
 	ErrEmptyCertificateID           = errors.New("EMPTY_CERT_ID")
@@ -33,6 +42,7 @@ var (
 	ErrCannotFormatEndpoint = errors.New("CANNOT_FORMAT_ENDPOINT")
 	ErrCannotReadBody       = errors.New("CANNOT_READ_RESPONSE_BODY")
 	ErrCannotUnmarshalBody  = errors.New("CANNOT_UNMARSHAL_JSON")
+	ErrNotAttempted         = errors.New("NOT_ATTEMPTED")
 
 	// Errors from Backend.
 	ErrBadRequestStatusText    = errors.New("INCORRECT_REQUEST")
@@ -51,10 +61,16 @@ var (
 	stringToError = map[string]error{
 		ErrClientNoAuthOpts.Error():     ErrClientNoAuthOpts,
 		ErrAuthTokenUnathorized.Error(): ErrAuthTokenUnathorized,
+		ErrCannotIssueAuthToken.Error(): ErrCannotIssueAuthToken,
+
+		ErrClientBadConfig.Error(): ErrClientBadConfig,
 
 		ErrEmptySecretName.Error():         ErrEmptySecretName,
+		ErrInvalidSecretName.Error():       ErrInvalidSecretName,
 		ErrEmptySecretValue.Error():        ErrEmptySecretValue,
 		ErrCannotMarshalSecretBody.Error(): ErrCannotMarshalSecretBody,
+		ErrInvalidSecretValue.Error():      ErrInvalidSecretValue,
+		ErrSecretOverwritten.Error():       ErrSecretOverwritten,
 
 		ErrEmptyCertificateID.Error():           ErrEmptyCertificateID,
 		ErrEmptyCertificateName.Error():         ErrEmptyCertificateName,
@@ -67,6 +83,7 @@ var (
 		ErrCannotFormatEndpoint.Error(): ErrCannotFormatEndpoint,
 		ErrCannotReadBody.Error():       ErrCannotReadBody,
 		ErrCannotUnmarshalBody.Error():  ErrCannotUnmarshalBody,
+		ErrNotAttempted.Error():         ErrNotAttempted,
 
 		ErrBadRequestStatusText.Error():    ErrBadRequestStatusText,
 		ErrInternalErrorStatusText.Error(): ErrInternalErrorStatusText,
@@ -102,12 +119,88 @@ func GetError(errorString string) error {
 type Error struct {
 	Err  error
 	Desc string
+
+	// StatusCode is an HTTP status of a response, 0 if no response has been received.
+	StatusCode int
+
+	// RequestID is an ID of a request assigned by the backend, quote it in support tickets.
+	RequestID string
+
+	// Method and Endpoint describe a failed request, Endpoint has no credentials and query.
+	// Both are empty for errors, that are not related to a request (like validation errors).
+	Method   string
+	Endpoint string
+
+	// Response is an error returned by the backend, nil if the backend hasn't described an error.
+	Response *ErrResponse
+
+	// Cause is an error, that has made a request fail before a response has been received
+	// (like context.Canceled or a network error), nil otherwise. It is matched by errors.Is and errors.As.
+	Cause error
+
+	// ContextDone is set, if a request has been stopped, because the caller's context has been canceled
+	// or its deadline has been exceeded. Timeouts of an http.Client don't set it.
+	ContextDone bool
 }
 
 func (e Error) Error() string {
-	return fmt.Sprintf("secretsmanager-go: error — %s: %s", e.Err.Error(), e.Desc)
+	msg := fmt.Sprintf("secretsmanager-go: error — %s: %s", e.Err.Error(), e.Desc)
+
+	var details []string
+	if len(e.Method) > 0 {
+		details = append(details, e.Method+" "+e.Endpoint)
+	}
+	if e.StatusCode != 0 {
+		details = append(details, fmt.Sprintf("status %d", e.StatusCode))
+	}
+	if len(e.RequestID) > 0 {
+		details = append(details, "request id "+e.RequestID)
+	}
+
+	if len(details) > 0 {
+		msg += " [" + strings.Join(details, ", ") + "]"
+	}
+	return msg
+}
+
+// Retryable reports whether a failed request may succeed, if it is made again later:
+// the backend is throttling requests or is temporarily unavailable, or a response hasn't been received.
+// Validation, authorization and not found errors are never retryable, neither are requests,
+// that have been stopped by the caller's context (see ContextDone).
+func (e Error) Retryable() bool {
+	if e.ContextDone {
+		return false
+	}
+
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return errors.Is(e.Err, ErrTooManyRequestsText) || errors.Is(e.Err, ErrCannotDoRequest)
 }
 
 func (e Error) Is(err error) bool {
 	return errors.Is(e.Err, err)
 }
+
+// Unwrap returns Cause, so errors.Is(err, context.Canceled) works for requests, that have been canceled.
+func (e Error) Unwrap() error {
+	return e.Cause
+}
+
+// Code returns a code of an error returned by the SDK (like NOT_FOUND or OVER_QUOTAS),
+// or an empty string, if err is nil or not a secretsmanagererrors.Error.
+func Code(err error) string {
+	var smErr Error
+	if !errors.As(err, &smErr) || smErr.Err == nil {
+		return ""
+	}
+	return smErr.Err.Error()
+}
+
+// Retryable reports whether err is a secretsmanagererrors.Error, that is retryable, see Error.Retryable.
+func Retryable(err error) bool {
+	var smErr Error
+	return errors.As(err, &smErr) && smErr.Retryable()
+}
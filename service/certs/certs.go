@@ -7,23 +7,63 @@ import (
 	"net/http"
 	"net/url"
 
+	"github.com/selectel/secretsmanager-go/internal/cache"
 	"github.com/selectel/secretsmanager-go/internal/httpclient"
 	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
 )
 
 const apiVersion = "v1"
 
+// Names of operations, that are passed to middlewares.
+const (
+	OperationDelete          = "certs.Delete"
+	OperationGet             = "certs.Get"
+	OperationUpdateVersion   = "certs.UpdateVersion"
+	OperationUpdateName      = "certs.UpdateName"
+	OperationGetPublicCerts  = "certs.GetPublicCerts"
+	OperationRemoveConsumers = "certs.RemoveConsumers"
+	OperationAddConsumers    = "certs.AddConsumers"
+	OperationGetPKCS12Bundle = "certs.GetPKCS12Bundle"
+	OperationGetPrivateKey   = "certs.GetPrivateKey"
+	OperationList            = "certs.List"
+	OperationCreate          = "certs.Create"
+)
+
 // Service implements Secrets Manager that is responsible for handling certificates operations.
 type Service struct {
 	apiURLUserCertificates string
 	httpClient             *httpclient.HTTPClient
+	cache                  *cache.Cache[Certificate]
 }
 
-func New(url string, client *httpclient.HTTPClient) *Service {
-	return &Service{
+// Option is a functional parameter for New.
+type Option func(*Service)
+
+// WithCache caches certificates returned by Get, see cache.Options for lifetimes of entries.
+func WithCache(opts cache.Options) Option {
+	return func(s *Service) {
+		s.cache = cache.New[Certificate](opts)
+	}
+}
+
+func New(url string, client *httpclient.HTTPClient, opts ...Option) *Service {
+	s := &Service{
 		apiURLUserCertificates: url,
 		httpClient:             client,
 	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// invalidate drops the cached certificate after a write, the write may have been applied even if it has failed.
+func (s Service) invalidate(id string) {
+	if s.cache != nil {
+		s.cache.Invalidate(id)
+	}
 }
 
 func (s Service) Delete(ctx context.Context, id string) error {
@@ -42,7 +82,8 @@ func (s Service) Delete(ctx context.Context, id string) error {
 		}
 	}
 
-	_, err = s.httpClient.DoRequest(ctx, http.MethodDelete, endpoint, nil)
+	_, err = s.httpClient.DoRequest(ctx, OperationDelete, id, http.MethodDelete, endpoint, nil)
+	s.invalidate(id)
 	if err != nil {
 		return err //nolint:wrapcheck // DoRequest already wraps the error.
 	}
@@ -50,7 +91,22 @@ func (s Service) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// Get returns the certificate.
+// If the service is created with WithCache, the certificate may be returned from the cache.
 func (s Service) Get(ctx context.Context, id string) (Certificate, error) {
+	if s.cache == nil || len(id) == 0 {
+		return s.get(ctx, id)
+	}
+
+	crt, err := s.cache.Get(ctx, id, func(ctx context.Context) (Certificate, error) {
+		return s.get(ctx, id)
+	})
+
+	return crt.clone(), err //nolint:wrapcheck // get already wraps the error.
+}
+
+// get requests the certificate bypassing the cache.
+func (s Service) get(ctx context.Context, id string) (Certificate, error) {
 	if len(id) == 0 {
 		return Certificate{}, secretsmanagererrors.Error{
 			Err:  secretsmanagererrors.ErrEmptyCertificateID,
@@ -66,7 +122,7 @@ func (s Service) Get(ctx context.Context, id string) (Certificate, error) {
 		}
 	}
 
-	respBody, err := s.httpClient.DoRequest(ctx, http.MethodGet, endpoint, nil)
+	respBody, err := s.httpClient.DoRequest(ctx, OperationGet, id, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return Certificate{}, err //nolint:wrapcheck // DoRequest already wraps the error.
 	}
@@ -112,7 +168,8 @@ func (s Service) UpdateVersion(ctx context.Context, id string, pem UpdateCertifi
 	}
 
 	reqBody := bytes.NewReader(marshalled)
-	_, err = s.httpClient.DoRequest(ctx, http.MethodPost, endpoint, reqBody)
+	_, err = s.httpClient.DoRequest(ctx, OperationUpdateVersion, id, http.MethodPost, endpoint, reqBody)
+	s.invalidate(id)
 	if err != nil {
 		return err //nolint:wrapcheck // DoRequest already wraps the error.
 	}
@@ -157,7 +214,8 @@ func (s Service) UpdateName(ctx context.Context, id, name string) error {
 
 	reqBody := bytes.NewReader(marshalled)
 
-	_, err = s.httpClient.DoRequest(ctx, http.MethodPut, endpoint, reqBody)
+	_, err = s.httpClient.DoRequest(ctx, OperationUpdateName, id, http.MethodPut, endpoint, reqBody)
+	s.invalidate(id)
 	if err != nil {
 		return err //nolint:wrapcheck // DoRequest already wraps the error.
 	}
@@ -181,7 +239,7 @@ func (s Service) GetPublicCerts(ctx context.Context, id string) (string, error)
 		}
 	}
 
-	respBody, err := s.httpClient.DoRequest(ctx, http.MethodGet, endpoint, nil)
+	respBody, err := s.httpClient.DoRequest(ctx, OperationGetPublicCerts, id, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return "", err //nolint:wrapcheck // DoRequest already wraps the error.
 	}
@@ -215,7 +273,8 @@ func (s Service) RemoveConsumers(ctx context.Context, id string, consumers Remov
 
 	reqBody := bytes.NewReader(marshalled)
 
-	_, err = s.httpClient.DoRequest(ctx, http.MethodDelete, endpoint, reqBody)
+	_, err = s.httpClient.DoRequest(ctx, OperationRemoveConsumers, id, http.MethodDelete, endpoint, reqBody)
+	s.invalidate(id)
 	if err != nil {
 		return err //nolint:wrapcheck // DoRequest already wraps the error.
 	}
@@ -249,7 +308,8 @@ func (s Service) AddConsumers(ctx context.Context, id string, consumers AddConsu
 
 	reqBody := bytes.NewReader(marshalled)
 
-	_, err = s.httpClient.DoRequest(ctx, http.MethodPut, endpoint, reqBody)
+	_, err = s.httpClient.DoRequest(ctx, OperationAddConsumers, id, http.MethodPut, endpoint, reqBody)
+	s.invalidate(id)
 	if err != nil {
 		return err //nolint:wrapcheck // DoRequest already wraps the error.
 	}
@@ -273,7 +333,7 @@ func (s Service) GetPKCS12Bundle(ctx context.Context, id string) ([]byte, error)
 		}
 	}
 
-	respBody, err := s.httpClient.DoRequest(ctx, http.MethodGet, endpoint, nil)
+	respBody, err := s.httpClient.DoRequest(ctx, OperationGetPKCS12Bundle, id, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return []byte{}, err //nolint:wrapcheck // DoRequest already wraps the error.
 	}
@@ -297,7 +357,7 @@ func (s Service) GetPrivateKey(ctx context.Context, id string) (string, error) {
 		}
 	}
 
-	respBody, err := s.httpClient.DoRequest(ctx, http.MethodGet, endpoint, nil)
+	respBody, err := s.httpClient.DoRequest(ctx, OperationGetPrivateKey, id, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return "", err //nolint:wrapcheck // DoRequest already wraps the error.
 	}
@@ -314,7 +374,7 @@ func (s Service) List(ctx context.Context) (GetCertificatesResponse, error) {
 		}
 	}
 
-	respBody, err := s.httpClient.DoRequest(ctx, http.MethodGet, endpoint, nil)
+	respBody, err := s.httpClient.DoRequest(ctx, OperationList, "", http.MethodGet, endpoint, nil)
 	if err != nil {
 		return GetCertificatesResponse{}, err //nolint:wrapcheck // DoRequest already wraps the error.
 	}
@@ -353,7 +413,7 @@ func (s Service) Create(ctx context.Context, ucr CreateCertificateRequest) (Cert
 	}
 	reqBody := bytes.NewReader(marshalled)
 
-	respBody, err := s.httpClient.DoRequest(ctx, http.MethodPost, endpoint, reqBody)
+	respBody, err := s.httpClient.DoRequest(ctx, OperationCreate, "", http.MethodPost, endpoint, reqBody)
 	if err != nil {
 		return Certificate{}, err //nolint:wrapcheck // DoRequest already wraps the error.
 	}
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/suite"
 
 	"github.com/selectel/secretsmanager-go/internal/auth"
+	"github.com/selectel/secretsmanager-go/internal/cache"
 	"github.com/selectel/secretsmanager-go/internal/httpclient"
 	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
 	"github.com/selectel/secretsmanager-go/service/certs"
@@ -530,3 +531,76 @@ func (suite *CertsSuite) TestGetPKCS12Bundle() {
 	suite.Require().ErrorIs(err, nil)
 	suite.Equal(testP12, got)
 }
+
+func (suite *CertsSuite) TestAll() {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		opts     certs.ListOptions
+		expNames []string
+	}{
+		"All":            {certs.ListOptions{SortBy: certs.SortByName}, []string{"Zeliboba"}},
+		"Prefix":         {certs.ListOptions{Prefix: "Zeli"}, []string{"Zeliboba"}},
+		"Other Type":     {certs.ListOptions{Type: "ECDSA"}, nil},
+		"Created After":  {certs.ListOptions{CreatedAfter: since}, []string{"Zeliboba"}},
+		"Created Before": {certs.ListOptions{CreatedAfter: since.AddDate(1, 0, 0)}, nil},
+	}
+
+	for name, test := range tests {
+		suite.T().Run(name, func(t *testing.T) {
+			gock.New(testDummyEndpoint).
+				Get("/certs").
+				Reply(http.StatusOK).
+				File("./fixtures/certs-response-data.json")
+
+			var names []string
+			for crt, err := range suite.service.All(context.Background(), test.opts) {
+				suite.Require().NoError(err)
+				names = append(names, crt.Name)
+			}
+
+			suite.Equal(test.expNames, names)
+		})
+	}
+}
+
+func (suite *CertsSuite) TestGetCachedInvalidatedByUpdateName() {
+	auth, err := auth.NewKeystoneTokenAuth("dummy")
+	suite.Require().NoError(err)
+
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	gock.InterceptClient(httpClient)
+	service := certs.New(
+		testDummyEndpoint,
+		httpclient.New(auth, httpClient),
+		certs.WithCache(cache.Options{TTL: time.Hour}),
+	)
+
+	gock.New(testDummyEndpoint).
+		Get("/v1/cert/" + testDummyID).
+		Reply(http.StatusOK).
+		File("./fixtures/cert-response-data.json")
+	gock.New(testDummyEndpoint).
+		Put("/v1/cert/" + testDummyID).
+		Reply(http.StatusOK)
+	gock.New(testDummyEndpoint).
+		Get("/v1/cert/" + testDummyID).
+		Reply(http.StatusOK).
+		JSON(map[string]any{"id": testDummyID, "name": "renamed"})
+
+	ctx := context.Background()
+	for range 2 {
+		crt, err := service.Get(ctx, testDummyID)
+		suite.Require().NoError(err)
+		suite.Equal(testCert, crt)
+
+		// Changes of a returned certificate don't get to the cache.
+		crt.DNSNames[0] = "changed"
+	}
+
+	err = service.UpdateName(ctx, testDummyID, "renamed")
+	suite.Require().NoError(err)
+
+	crt, err := service.Get(ctx, testDummyID)
+	suite.Require().NoError(err)
+	suite.Equal("renamed", crt.Name)
+}
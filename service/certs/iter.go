@@ -0,0 +1,101 @@
+package certs
+
+import (
+	"context"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/selectel/secretsmanager-go/internal/listing"
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+// SortBy — a field certificates are sorted by in All.
+type SortBy string
+
+const (
+	// SortByName sorts certificates by their names.
+	SortByName SortBy = "name"
+	// SortByCreatedAt sorts certificates by the start of their validity.
+	SortByCreatedAt SortBy = "created_at"
+)
+
+// ListOptions — filters and sorting of certificates returned by All, zero values disable them.
+type ListOptions struct {
+	// Prefix keeps certificates, which names start with it.
+	Prefix string
+
+	// Type keeps certificates with a private key of the given type, like RSA.
+	Type string
+
+	// CreatedAfter keeps certificates, which validity starts after it.
+	CreatedAfter time.Time
+
+	// SortBy sorts certificates by the given field, certificates are returned in the order of the API if it is empty.
+	SortBy     SortBy
+	Descending bool
+}
+
+// All returns an iterator over certificates, that match opts. Certificates are requested, when the iteration starts.
+// It makes a single List request and holds all certificates of the response in memory, since Certificates Manager
+// doesn't support pagination, so it doesn't save memory on large projects, only filters and sorts.
+// The iteration stops after the first error or when ctx is done.
+func (s Service) All(ctx context.Context, opts ListOptions) iter.Seq2[Certificate, error] {
+	fetch := func(ctx context.Context, _ string) ([]Certificate, string, error) {
+		crts, err := s.List(ctx)
+		return crts, "", err
+	}
+
+	return listing.Seq(ctx, fetch, listing.Options[Certificate]{
+		Keep:    opts.keep,
+		Compare: opts.compare(),
+	})
+}
+
+func (opts ListOptions) keep(crt Certificate) (bool, error) {
+	if !strings.HasPrefix(crt.Name, opts.Prefix) {
+		return false, nil
+	}
+
+	if len(opts.Type) > 0 && crt.PrivateKey.Type != opts.Type {
+		return false, nil
+	}
+
+	if !opts.CreatedAfter.IsZero() {
+		notBefore, err := time.Parse(time.RFC3339, crt.Validity.NotBefore)
+		if err != nil {
+			return false, secretsmanagererrors.Error{
+				Err:  secretsmanagererrors.ErrCannotUnmarshalBody,
+				Desc: "invalid notBefore of a certificate: " + err.Error(),
+			}
+		}
+		if !notBefore.After(opts.CreatedAfter) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (opts ListOptions) compare() func(a, b Certificate) int {
+	var compare func(a, b Certificate) int
+	switch opts.SortBy {
+	case SortByName:
+		compare = func(a, b Certificate) int {
+			return strings.Compare(a.Name, b.Name)
+		}
+	case SortByCreatedAt:
+		compare = func(a, b Certificate) int {
+			return listing.CompareTimes(a.Validity.NotBefore, b.Validity.NotBefore)
+		}
+	default:
+		return nil
+	}
+
+	if opts.Descending {
+		return func(a, b Certificate) int {
+			return compare(b, a)
+		}
+	}
+	return compare
+}
@@ -1,5 +1,13 @@
 package certs
 
+import (
+	"log/slog"
+	"slices"
+)
+
+// redacted replaces private keys, when entities are logged with log/slog.
+const redacted = "[REDACTED]"
+
 // Certificate entity received by the user when making a request
 // GET /cert/{id}.
 type Certificate struct {
@@ -14,6 +22,18 @@ type Certificate struct {
 	Version    int64      `json:"version"`
 }
 
+// clone returns a copy of the certificate, that doesn't share slices with it,
+// so callers can't modify a cached certificate.
+func (c Certificate) clone() Certificate {
+	c.Consumers = slices.Clone(c.Consumers)
+	c.DNSNames = slices.Clone(c.DNSNames)
+	c.IssuedBy.Country = slices.Clone(c.IssuedBy.Country)
+	c.IssuedBy.Locality = slices.Clone(c.IssuedBy.Locality)
+	c.IssuedBy.StreetAddress = slices.Clone(c.IssuedBy.StreetAddress)
+
+	return c
+}
+
 type Consumer struct {
 	ID     string `json:"id"`
 	Region string `json:"region"`
@@ -48,6 +68,19 @@ type Pem struct {
 	PrivateKey   string   `json:"private_key"`
 }
 
+// LogValue implements slog.LogValuer, so the private key never gets to logs.
+func (r UpdateCertificateVersionRequest) LogValue() slog.Value {
+	return slog.GroupValue(slog.Any("pem", r.Pem))
+}
+
+// LogValue implements slog.LogValuer, so the private key never gets to logs.
+func (p Pem) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Any("certificates", p.Certificates),
+		slog.String("private_key", redacted),
+	)
+}
+
 // UpdateCertificateNameRequest entity send by the user when making a request
 // PUT /cert/{id}.
 type UpdateCertificateNameRequest struct {
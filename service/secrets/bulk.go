@@ -0,0 +1,213 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+const (
+	// defaultBulkWorkers represents the default number of concurrent requests of bulk operations.
+	defaultBulkWorkers = 8
+
+	// rollbackTimeout limits a rollback of a bulk operation, it isn't bound to ctx of the operation,
+	// as the operation has often failed because ctx is done.
+	rollbackTimeout = time.Minute
+)
+
+// BulkOptions — options of GetMany, CreateMany and DeleteMany.
+type BulkOptions struct {
+	// Workers limits the number of concurrent requests, defaultBulkWorkers is used if it is 0.
+	Workers int
+
+	// Atomic makes CreateMany and DeleteMany all-or-nothing: if any item fails,
+	// writes that have succeeded are rolled back, even if ctx is done. It is ignored by GetMany.
+	Atomic bool
+}
+
+// ItemResult — a result of a bulk operation for a single key.
+type ItemResult struct {
+	Key string
+	Err error
+
+	// RolledBack is set, if the item has succeeded, but has been rolled back in the Atomic mode.
+	// RollbackErr is set, if the rollback has failed, so the item is left applied.
+	RolledBack  bool
+	RollbackErr error
+}
+
+// GetResult — a result of GetMany for a single key.
+type GetResult struct {
+	Key    string
+	Secret Secret
+	Err    error
+}
+
+// GetMany gets secrets concurrently and returns results in the order of keys.
+// A failure of one key doesn't stop the others, all failures are joined into the returned error.
+func (s Service) GetMany(ctx context.Context, keys []string, opts BulkOptions) (_ []GetResult, err error) {
+	ctx, end := s.httpClient.StartOperation(ctx, OperationGetMany, "")
+	defer func() { end(err) }()
+
+	return getMany(ctx, keys, opts.Workers, s.Get)
+}
+
+func getMany(
+	ctx context.Context, keys []string, workers int, get func(ctx context.Context, key string) (Secret, error),
+) ([]GetResult, error) {
+	results := make([]GetResult, len(keys))
+	forEach(len(keys), workers, func(i int) {
+		sc, err := get(ctx, keys[i])
+		results[i] = GetResult{Key: keys[i], Secret: sc, Err: err}
+	})
+
+	errs := make([]error, 0, len(results))
+	for _, res := range results {
+		errs = append(errs, itemError(res.Key, res.Err))
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// CreateMany creates secrets concurrently and returns results in the order of uscs.
+// A failure of one secret doesn't stop the others, all failures are joined into the returned error.
+// In the Atomic mode secrets, that have been created, are deleted, if any of them has failed.
+func (s Service) CreateMany(ctx context.Context, uscs []UserSecret, opts BulkOptions) (_ []ItemResult, err error) {
+	ctx, end := s.httpClient.StartOperation(ctx, OperationCreateMany, "")
+	defer func() { end(err) }()
+
+	results := make([]ItemResult, len(uscs))
+	forEach(len(uscs), opts.Workers, func(i int) {
+		results[i] = ItemResult{Key: uscs[i].Key, Err: s.Create(ctx, uscs[i])}
+	})
+
+	err = joinItemErrors(results)
+	if err != nil && opts.Atomic {
+		rollback(ctx, results, opts.Workers, func(ctx context.Context, i int) error {
+			return s.Delete(ctx, uscs[i].Key)
+		})
+	}
+
+	return results, err
+}
+
+// DeleteMany deletes secrets concurrently and returns results in the order of keys.
+// A failure of one key doesn't stop the others, all failures are joined into the returned error.
+// In the Atomic mode secrets are read before deletion and nothing is deleted, if any of them can't be read,
+// the others are reported with ErrNotAttempted then; secrets, that have been deleted, are created again,
+// if deletion of any of them has failed.
+// Recreated secrets have only their latest version, the history of versions is lost.
+func (s Service) DeleteMany(ctx context.Context, keys []string, opts BulkOptions) (_ []ItemResult, err error) {
+	ctx, end := s.httpClient.StartOperation(ctx, OperationDeleteMany, "")
+	defer func() { end(err) }()
+
+	var backups []GetResult
+	if opts.Atomic {
+		// Backups bypass the cache, so a stale value is never restored.
+		backups, err = getMany(ctx, keys, opts.Workers, s.get)
+		if err != nil {
+			return notAttempted(backups), err
+		}
+	}
+
+	results := make([]ItemResult, len(keys))
+	forEach(len(keys), opts.Workers, func(i int) {
+		results[i] = ItemResult{Key: keys[i], Err: s.Delete(ctx, keys[i])}
+	})
+
+	err = joinItemErrors(results)
+	if err != nil && opts.Atomic {
+		rollback(ctx, results, opts.Workers, func(ctx context.Context, i int) error {
+			backup := backups[i].Secret
+			return s.Create(ctx, UserSecret{
+				Key:         keys[i],
+				Description: backup.Description,
+				Value:       backup.Version.Value,
+			})
+		})
+	}
+
+	return results, err
+}
+
+// notAttempted returns results of DeleteMany, that has failed to read backups: failed items keep their errors,
+// the others are marked with ErrNotAttempted, as nothing has been deleted.
+func notAttempted(backups []GetResult) []ItemResult {
+	results := make([]ItemResult, len(backups))
+	for i, backup := range backups {
+		err := backup.Err
+		if err == nil {
+			err = secretsmanagererrors.Error{
+				Err:  secretsmanagererrors.ErrNotAttempted,
+				Desc: fmt.Sprintf("secret %s hasn't been deleted, as other secrets can't be read", backup.Key),
+			}
+		}
+		results[i] = ItemResult{Key: backup.Key, Err: err}
+	}
+
+	return results
+}
+
+// rollback undoes succeeded items with undo concurrently and marks them in results.
+// Undo is called with a context, that isn't canceled with ctx, but is limited by rollbackTimeout.
+func rollback(ctx context.Context, results []ItemResult, workers int, undo func(ctx context.Context, i int) error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+	defer cancel()
+
+	forEach(len(results), workers, func(i int) {
+		if results[i].Err != nil {
+			return
+		}
+
+		err := undo(ctx, i)
+		if err != nil {
+			results[i].RollbackErr = err
+			return
+		}
+		results[i].RolledBack = true
+	})
+}
+
+// forEach calls fn for indices from 0 to n-1 with at most workers concurrent calls.
+func forEach(n, workers int, fn func(i int)) {
+	if workers <= 0 {
+		workers = defaultBulkWorkers
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func joinItemErrors(results []ItemResult) error {
+	errs := make([]error, 0, len(results))
+	for _, res := range results {
+		errs = append(errs, itemError(res.Key, res.Err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// itemError prefixes an error with a key, so failures in a joined error can be told apart.
+func itemError(key string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return fmt.Errorf("%s: %w", key, err)
+}
@@ -0,0 +1,110 @@
+package secrets
+
+import (
+	"context"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/selectel/secretsmanager-go/internal/listing"
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+// SortBy — a field secrets are sorted by in All.
+type SortBy string
+
+const (
+	// SortByName sorts secrets by their keys.
+	SortByName SortBy = "name"
+	// SortByCreatedAt sorts secrets by their creation time.
+	SortByCreatedAt SortBy = "created_at"
+)
+
+// ListOptions — filters and sorting of secrets returned by All, zero values disable them.
+type ListOptions struct {
+	// Prefix keeps secrets, which keys start with it, see ListPrefix for a prefix of hierarchical keys.
+	Prefix string
+
+	// Type keeps secrets of the given type.
+	Type string
+
+	// CreatedAfter keeps secrets created after it.
+	CreatedAfter time.Time
+
+	// SortBy sorts secrets by the given field, secrets are returned in the order of the API if it is empty.
+	SortBy     SortBy
+	Descending bool
+}
+
+// All returns an iterator over secrets, that match opts. Secrets are requested, when the iteration starts.
+// It makes a single List request and holds all secrets of the response in memory, since Secrets Manager
+// doesn't support pagination, so it doesn't save memory on large projects, only filters and sorts.
+// The iteration stops after the first error or when ctx is done.
+func (s Service) All(ctx context.Context, opts ListOptions) iter.Seq2[Key, error] {
+	fetch := func(ctx context.Context, _ string) ([]Key, string, error) {
+		sc, err := s.List(ctx)
+		return sc.Keys, "", err
+	}
+
+	return listing.Seq(ctx, fetch, listing.Options[Key]{
+		Keep:    opts.keep,
+		Compare: opts.compare(),
+	})
+}
+
+func (opts ListOptions) keep(key Key) (bool, error) {
+	if !strings.HasPrefix(key.Name, opts.Prefix) {
+		return false, nil
+	}
+
+	if len(opts.Type) > 0 && key.Type != opts.Type {
+		return false, nil
+	}
+
+	if !opts.CreatedAfter.IsZero() {
+		createdAt, err := key.Metadata.createdAt()
+		if err != nil {
+			return false, err
+		}
+		if !createdAt.After(opts.CreatedAfter) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (opts ListOptions) compare() func(a, b Key) int {
+	var compare func(a, b Key) int
+	switch opts.SortBy {
+	case SortByName:
+		compare = func(a, b Key) int {
+			return strings.Compare(a.Name, b.Name)
+		}
+	case SortByCreatedAt:
+		compare = func(a, b Key) int {
+			return listing.CompareTimes(a.Metadata.CreatedAt, b.Metadata.CreatedAt)
+		}
+	default:
+		return nil
+	}
+
+	if opts.Descending {
+		return func(a, b Key) int {
+			return compare(b, a)
+		}
+	}
+	return compare
+}
+
+func (m SecretMetadata) createdAt() (time.Time, error) {
+	createdAt, err := time.Parse(time.RFC3339, m.CreatedAt)
+	if err != nil {
+		return time.Time{}, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrCannotUnmarshalBody,
+			Desc: "invalid created_at of a secret: " + err.Error(),
+		}
+	}
+
+	return createdAt, nil
+}
@@ -0,0 +1,165 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+// KeySeparator separates segments of hierarchical keys like "payments/prod/db/password".
+const KeySeparator = "/"
+
+// secretEndpoint returns URL of the secret with the given key, elems are appended after the key.
+// Segments of the key are escaped one by one, so slashes are kept as path separators,
+// while other reserved characters (like "?", "#", "%" or spaces) are escaped.
+func (s Service) secretEndpoint(key string, elems ...string) (string, error) {
+	segments, err := splitKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	elems = append([]string{apiVersion, strings.Join(segments, KeySeparator)}, elems...)
+	endpoint, err := url.JoinPath(s.apiURLSecrets, elems...)
+	if err != nil {
+		return "", secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrCannotFormatEndpoint,
+			Desc: err.Error(),
+		}
+	}
+
+	return endpoint, nil
+}
+
+// splitKey splits a hierarchical key into segments and checks, that none of them is empty, "." or "..",
+// as such keys would be resolved to another secret.
+func splitKey(key string) ([]string, error) {
+	if len(key) == 0 {
+		return nil, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrEmptySecretName,
+			Desc: "field name in secret is empty",
+		}
+	}
+
+	segments := strings.Split(key, KeySeparator)
+	for _, segment := range segments {
+		if len(segment) == 0 || segment == "." || segment == ".." {
+			return nil, secretsmanagererrors.Error{
+				Err:  secretsmanagererrors.ErrInvalidSecretName,
+				Desc: fmt.Sprintf("key %q has an empty, \".\" or \"..\" segment", key),
+			}
+		}
+	}
+
+	return segments, nil
+}
+
+// inSubtree reports whether key is prefix itself or is nested under it.
+func inSubtree(key, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, KeySeparator)
+	if len(prefix) == 0 {
+		return true
+	}
+
+	return key == prefix || strings.HasPrefix(key, prefix+KeySeparator)
+}
+
+// ListPrefix returns secrets, which keys are prefix itself or are nested under it:
+// "payments/prod" matches "payments/prod" and "payments/prod/db/password", but not "payments/production".
+// An empty prefix matches all secrets.
+func (s Service) ListPrefix(ctx context.Context, prefix string) (_ Secrets, err error) {
+	ctx, end := s.httpClient.StartOperation(ctx, OperationListPrefix, prefix)
+	defer func() { end(err) }()
+
+	all, err := s.List(ctx)
+	if err != nil {
+		return Secrets{}, err
+	}
+
+	var sc Secrets
+	for _, key := range all.Keys {
+		if inSubtree(key.Name, prefix) {
+			sc.Keys = append(sc.Keys, key)
+		}
+	}
+
+	return sc, nil
+}
+
+// ListChildren returns sorted names of immediate children of prefix, like ls does:
+// a secret "payments/prod/token" is returned as "token", while secrets nested deeper,
+// like "payments/prod/db/password", are grouped into "db/".
+func (s Service) ListChildren(ctx context.Context, prefix string) (_ []string, err error) {
+	ctx, end := s.httpClient.StartOperation(ctx, OperationListChildren, prefix)
+	defer func() { end(err) }()
+
+	sc, err := s.ListPrefix(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix = strings.TrimSuffix(prefix, KeySeparator)
+	if len(prefix) > 0 {
+		prefix += KeySeparator
+	}
+
+	seen := map[string]bool{}
+	children := []string{}
+	for _, key := range sc.Keys {
+		rest, ok := strings.CutPrefix(key.Name, prefix)
+		if !ok || len(rest) == 0 {
+			continue
+		}
+
+		child, _, nested := strings.Cut(rest, KeySeparator)
+		if nested {
+			child += KeySeparator
+		}
+
+		if !seen[child] {
+			seen[child] = true
+			children = append(children, child)
+		}
+	}
+	sort.Strings(children)
+
+	return children, nil
+}
+
+// DeletePrefix deletes prefix and all secrets nested under it and returns keys of deleted secrets.
+// It stops at the first failed deletion, secrets deleted before it are returned along with the error.
+// An empty prefix is rejected, so all secrets can't be deleted by mistake.
+func (s Service) DeletePrefix(ctx context.Context, prefix string) (_ []string, err error) {
+	ctx, end := s.httpClient.StartOperation(ctx, OperationDeletePrefix, prefix)
+	defer func() { end(err) }()
+
+	if len(strings.TrimSuffix(prefix, KeySeparator)) == 0 {
+		return nil, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrEmptySecretName,
+			Desc: "prefix is empty",
+		}
+	}
+
+	sc, err := s.ListPrefix(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := make([]string, 0, len(sc.Keys))
+	for _, key := range sc.Keys {
+		err = s.Delete(ctx, key.Name)
+		if err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, key.Name)
+	}
+
+	return deleted, nil
+}
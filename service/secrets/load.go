@@ -0,0 +1,361 @@
+package secrets
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+// loadTag is a struct tag, that binds a field to a secret.
+const loadTag = "secret"
+
+// binding — a field bound to a secret by a struct tag.
+type binding struct {
+	field    reflect.Value
+	path     string // A name of the field for errors, like "DB.Password".
+	key      string
+	version  *uint
+	required bool
+	fallback *string
+}
+
+// secretRef — a secret or its specific version, that is fetched once, even if it is bound to several fields.
+type secretRef struct {
+	key     string
+	version uint
+	pinned  bool
+}
+
+// Load fills fields of a struct dst points to from secrets, they are bound with a struct tag:
+//
+//	type Config struct {
+//		DBPassword string        `secret:"payments/db/password,required"`
+//		Timeout    time.Duration `secret:"payments/timeout,default=5s"`
+//		APIKey     []byte        `secret:"payments/api-key,version=3"`
+//		Cache      struct {
+//			Token string `secret:"token"` // payments/cache/token
+//		} `secret:"payments/cache"`
+//	}
+//
+// Options of the tag are required (a secret has to exist), version=N (a version is pinned)
+// and default=V (a value, if a secret doesn't exist), default has to be the last option.
+// Tagged nested structs prefix keys of their fields, untagged ones are filled with the same prefix.
+// A nil pointer to a nested struct is allocated only, if any of its fields is bound,
+// and a struct, that contains its own type, isn't walked into it again.
+// Fields can be strings, []byte, booleans, numbers, time.Duration and encoding.TextUnmarshaler.
+//
+// Secrets are requested concurrently. Load doesn't stop at the first failure, all missing required secrets
+// are reported in a single ErrNotFoundStatusText error joined with other failures.
+func Load(ctx context.Context, s *Service, dst any) (err error) {
+	ctx, end := s.httpClient.StartOperation(ctx, OperationLoad, "")
+	defer func() { end(err) }()
+
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrInternalAppError,
+			Desc: fmt.Sprintf("Load expects a non-nil pointer to a struct, got %T", dst),
+		}
+	}
+
+	bindings, err := collectBindings(rv.Elem(), "", "", map[reflect.Type]bool{})
+	if err != nil {
+		return err
+	}
+
+	refs := make([]secretRef, 0, len(bindings))
+	seen := map[secretRef]bool{}
+	for _, b := range bindings {
+		ref := b.ref()
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	values := make([][]byte, len(refs))
+	errs := make([]error, len(refs))
+	forEach(len(refs), defaultBulkWorkers, func(i int) {
+		values[i], errs[i] = s.fetch(ctx, refs[i])
+	})
+
+	fetched := make(map[secretRef]int, len(refs))
+	for i, ref := range refs {
+		fetched[ref] = i
+	}
+
+	var (
+		missing  []string
+		failures []error
+	)
+	for _, b := range bindings {
+		i := fetched[b.ref()]
+		value, err := values[i], errs[i]
+
+		switch {
+		case errors.Is(err, secretsmanagererrors.ErrNotFoundStatusText) && b.fallback != nil:
+			value = []byte(*b.fallback)
+		case errors.Is(err, secretsmanagererrors.ErrNotFoundStatusText):
+			if b.required {
+				missing = append(missing, b.key)
+			}
+			continue
+		case err != nil:
+			failures = append(failures, fmt.Errorf("%s: %w", b.path, err))
+			continue
+		}
+
+		err = setField(b.field, value)
+		if err != nil {
+			failures = append(failures, secretsmanagererrors.Error{
+				Err:  secretsmanagererrors.ErrInvalidSecretValue,
+				Desc: fmt.Sprintf("can't set %s from secret %s: %s", b.path, b.key, err),
+			})
+		}
+	}
+
+	if len(missing) > 0 {
+		failures = append([]error{secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrNotFoundStatusText,
+			Desc: "missing required secrets: " + strings.Join(missing, ", "),
+		}}, failures...)
+	}
+
+	return errors.Join(failures...)
+}
+
+func (b binding) ref() secretRef {
+	if b.version == nil {
+		return secretRef{key: b.key}
+	}
+
+	return secretRef{key: b.key, version: *b.version, pinned: true}
+}
+
+// fetch returns a value of the latest or a pinned version of a secret.
+func (s Service) fetch(ctx context.Context, ref secretRef) ([]byte, error) {
+	if ref.pinned {
+		sv, err := s.GetVersion(ctx, ref.key, ref.version)
+		return sv.Value, err
+	}
+
+	sc, err := s.Get(ctx, ref.key)
+	return sc.Version.Value, err
+}
+
+// collectBindings walks fields of a struct and returns tagged ones, nested structs are walked recursively.
+// Visiting holds types of structs on the current path: a type, that contains itself, isn't walked again.
+func collectBindings(rv reflect.Value, prefix, path string, visiting map[reflect.Type]bool) ([]binding, error) {
+	var bindings []binding
+
+	rt := rv.Type()
+	visiting[rt] = true
+	defer delete(visiting, rt)
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		field := rv.Field(i)
+		fieldPath := path + sf.Name
+		tag, tagged := sf.Tag.Lookup(loadTag)
+		if tag == "-" {
+			continue
+		}
+
+		if isNested(field) {
+			nestedPrefix := prefix
+			if tagged {
+				nestedPrefix = joinKey(prefix, tag)
+			}
+
+			nested, err := collectNested(field, nestedPrefix, fieldPath+".", visiting)
+			if err != nil {
+				return nil, err
+			}
+			bindings = append(bindings, nested...)
+			continue
+		}
+
+		if !tagged {
+			continue
+		}
+
+		b, err := parseTag(tag, prefix)
+		if err != nil {
+			return nil, secretsmanagererrors.Error{
+				Err:  secretsmanagererrors.ErrInternalAppError,
+				Desc: fmt.Sprintf("invalid tag of %s: %s", fieldPath, err),
+			}
+		}
+		b.field = field
+		b.path = fieldPath
+		bindings = append(bindings, b)
+	}
+
+	return bindings, nil
+}
+
+// collectNested returns bindings of a nested struct. A nil pointer to it is allocated only,
+// if any of its fields is bound, so pointers to structs without tags stay nil.
+func collectNested(field reflect.Value, prefix, path string, visiting map[reflect.Type]bool) ([]binding, error) {
+	ft := field.Type()
+	if ft.Kind() == reflect.Pointer {
+		ft = ft.Elem()
+	}
+	if visiting[ft] {
+		return nil, nil
+	}
+
+	if field.Kind() != reflect.Pointer {
+		return collectBindings(field, prefix, path, visiting)
+	}
+
+	if !field.IsNil() {
+		return collectBindings(field.Elem(), prefix, path, visiting)
+	}
+
+	ptr := reflect.New(ft)
+	bindings, err := collectBindings(ptr.Elem(), prefix, path, visiting)
+	if err != nil || len(bindings) == 0 {
+		return nil, err
+	}
+	field.Set(ptr)
+
+	return bindings, nil
+}
+
+// isNested reports whether a field is a struct (or a pointer to it), which fields are bound by themselves.
+func isNested(field reflect.Value) bool {
+	ft := field.Type()
+	if ft.Kind() == reflect.Pointer {
+		ft = ft.Elem()
+	}
+
+	if ft.Kind() != reflect.Struct {
+		return false
+	}
+
+	return !reflect.PointerTo(ft).Implements(reflect.TypeFor[encoding.TextUnmarshaler]())
+}
+
+// parseTag parses a tag like "payments/db/password,required,version=3,default=value".
+func parseTag(tag, prefix string) (binding, error) {
+	name, opts, _ := strings.Cut(tag, ",")
+	if len(name) == 0 {
+		return binding{}, errors.New("empty key")
+	}
+
+	b := binding{key: joinKey(prefix, name)}
+	for len(opts) > 0 {
+		if fallback, ok := strings.CutPrefix(opts, "default="); ok {
+			b.fallback = &fallback
+			break
+		}
+
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+
+		switch {
+		case opt == "required":
+			b.required = true
+		case strings.HasPrefix(opt, "version="):
+			version, err := strconv.ParseUint(strings.TrimPrefix(opt, "version="), 10, 0)
+			if err != nil {
+				return binding{}, fmt.Errorf("invalid version: %w", err)
+			}
+			v := uint(version)
+			b.version = &v
+		default:
+			return binding{}, fmt.Errorf("unknown option %q", opt)
+		}
+	}
+
+	return b, nil
+}
+
+func joinKey(prefix, name string) string {
+	if len(prefix) == 0 {
+		return name
+	}
+
+	return prefix + KeySeparator + name
+}
+
+// setField converts a value of a secret to the type of a field. Errors must not contain the value.
+func setField(field reflect.Value, value []byte) error {
+	if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if u.UnmarshalText(value) != nil {
+			return fmt.Errorf("invalid value for %s", field.Type())
+		}
+		return nil
+	}
+
+	if field.Type() == reflect.TypeFor[time.Duration]() {
+		d, err := time.ParseDuration(string(value))
+		if err != nil {
+			return errors.New("invalid duration")
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	str := strings.TrimSpace(string(value))
+
+	switch field.Kind() { //nolint:exhaustive // Other kinds are not supported.
+	case reflect.String:
+		field.SetString(string(value))
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported type %s", field.Type())
+		}
+		field.SetBytes(value)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(str)
+		if err != nil {
+			return errors.New("invalid boolean")
+		}
+		field.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(str, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", field.Type(), numError(err))
+		}
+		field.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(str, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", field.Type(), numError(err))
+		}
+		field.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(str, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", field.Type(), numError(err))
+		}
+		field.SetFloat(v)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return nil
+}
+
+// numError drops the parsed input from errors of strconv, as it is the value of a secret.
+func numError(err error) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return numErr.Err
+	}
+
+	return err
+}
@@ -0,0 +1,135 @@
+package secrets_test
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/h2non/gock"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+	"github.com/selectel/secretsmanager-go/service/secrets"
+)
+
+func mockSecretValue(key, value string) {
+	gock.New(testDummyEndpoint).
+		Get("/v1/" + key + "$").
+		Reply(http.StatusOK).
+		JSON(map[string]any{
+			"name":    key,
+			"version": map[string]any{"value": base64.StdEncoding.EncodeToString([]byte(value))},
+		})
+}
+
+func mockSecretNotFound(key string) {
+	gock.New(testDummyEndpoint).
+		Get("/v1/" + key + "$").
+		Reply(http.StatusNotFound).
+		JSON(map[string]string{"status_text": "NOT_FOUND"})
+}
+
+func (suite *SecretsSuite) TestLoad() {
+	mockSecretValue("payments/db/password", "pass")
+	mockSecretValue("payments/db/port", "5432")
+	mockSecretNotFound("payments/timeout")
+	mockSecretNotFound("payments/debug")
+	gock.New(testDummyEndpoint).
+		Get("/v1/payments/api-key/versions/3").
+		Reply(http.StatusOK).
+		JSON(map[string]any{"value": base64.StdEncoding.EncodeToString([]byte{0, 1, 2}), "version_id": 3})
+
+	type dbConfig struct {
+		Password string `secret:"password,required"`
+		Port     int    `secret:"port"`
+	}
+
+	var cfg struct {
+		DB      dbConfig      `secret:"payments/db"`
+		Timeout time.Duration `secret:"payments/timeout,default=5s"`
+		Debug   bool          `secret:"payments/debug"`
+		APIKey  []byte        `secret:"payments/api-key,version=3"`
+		Ignored string
+	}
+
+	err := secrets.Load(context.Background(), suite.service, &cfg)
+	suite.Require().NoError(err)
+
+	suite.Equal("pass", cfg.DB.Password)
+	suite.Equal(5432, cfg.DB.Port)
+	suite.Equal(5*time.Second, cfg.Timeout)
+	suite.False(cfg.Debug)
+	suite.Equal([]byte{0, 1, 2}, cfg.APIKey)
+}
+
+func (suite *SecretsSuite) TestLoadReportsAllMissing() {
+	mockSecretNotFound("first")
+	mockSecretNotFound("second")
+	mockSecretValue("port", "not-a-number-"+testDummyPassword)
+
+	var cfg struct {
+		First  string `secret:"first,required"`
+		Second string `secret:"second,required"`
+		Port   int    `secret:"port"`
+	}
+
+	err := secrets.Load(context.Background(), suite.service, &cfg)
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrNotFoundStatusText)
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrInvalidSecretValue)
+	suite.Require().ErrorContains(err, "missing required secrets: first, second")
+	suite.Require().ErrorContains(err, "can't set Port from secret port: invalid int: invalid syntax")
+	suite.NotContains(err.Error(), testDummyPassword)
+}
+
+func (suite *SecretsSuite) TestLoadInvalidTarget() {
+	var cfg struct {
+		Port int `secret:"port,unknown"`
+	}
+
+	err := secrets.Load(context.Background(), suite.service, cfg)
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrInternalAppError)
+
+	err = secrets.Load(context.Background(), suite.service, &cfg)
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrInternalAppError)
+	suite.Require().ErrorContains(err, `invalid tag of Port: unknown option "unknown"`)
+}
+
+func (suite *SecretsSuite) TestLoadNilPointers() {
+	mockSecretValue("db/password", "pass")
+
+	type dbConfig struct {
+		Password string `secret:"password"`
+	}
+
+	var cfg struct {
+		DB  *dbConfig `secret:"db"`
+		TLS *tls.Config
+	}
+
+	err := secrets.Load(context.Background(), suite.service, &cfg)
+	suite.Require().NoError(err)
+
+	suite.Require().NotNil(cfg.DB)
+	suite.Equal("pass", cfg.DB.Password)
+	suite.Nil(cfg.TLS)
+}
+
+type loadNode struct {
+	Name string `secret:"name"`
+	Next *loadNode
+}
+
+func (suite *SecretsSuite) TestLoadRecursiveType() {
+	mockSecretValue("node/name", "first")
+
+	var cfg struct {
+		Node loadNode `secret:"node"`
+	}
+
+	err := secrets.Load(context.Background(), suite.service, &cfg)
+	suite.Require().NoError(err)
+
+	suite.Equal("first", cfg.Node.Name)
+	suite.Nil(cfg.Node.Next)
+}
@@ -1,5 +1,10 @@
 package secrets
 
+import "log/slog"
+
+// redacted replaces secret values, when entities are logged with log/slog.
+const redacted = "[REDACTED]"
+
 // Secrets — entity received by the user when making a request
 // GET /.
 type Secrets struct {
@@ -25,9 +30,31 @@ type Secret struct {
 	Version     SecretVersion `json:"version"`
 }
 
+// SecretVersion — a version of the secret, it is also received by the user when making a request
+// GET /{key}/versions/{version_id}.
 type SecretVersion struct {
 	CreatedAt string `json:"created_at"`
-	Value     string `json:"value"` // The value of the secret in base64.
+	Value     []byte `json:"value"` // The value of the secret, it is decoded from base64 by the SDK.
+	VersionID uint   `json:"version_id"`
+
+	// RawValue is set, if the stored value isn't valid base64, so Value holds it as is.
+	// Such values have been written by Update of older versions of the SDK, that didn't encode them.
+	RawValue bool `json:"-"`
+
+	// StoredValue is the value as it is stored by Secrets Manager, before it is decoded from base64.
+	// A value written without encoding, that happens to be valid base64 (like "password"), is decoded
+	// into garbage without RawValue set, []byte(StoredValue) is the original value then.
+	StoredValue string `json:"-"`
+}
+
+// Versions — entity received by the user when making a request
+// GET /{key}/versions.
+type Versions struct {
+	Versions []VersionMetadata `json:"versions"`
+}
+
+type VersionMetadata struct {
+	CreatedAt string `json:"created_at"`
 	VersionID uint   `json:"version_id"`
 }
 
@@ -36,5 +63,32 @@ type SecretVersion struct {
 type UserSecret struct {
 	Key         string `json:"-"`
 	Description string `json:"description,omitempty"`
-	Value       string `json:"value"` // The value of the secret in base64.
+	Value       []byte `json:"value,omitempty"` // The value of the secret, it is encoded to base64 by the SDK.
+}
+
+// LogValue implements slog.LogValuer, so the value of the secret never gets to logs.
+func (s Secret) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("name", s.Name),
+		slog.String("description", s.Description),
+		slog.Any("version", s.Version),
+	)
+}
+
+// LogValue implements slog.LogValuer, so the value of the secret never gets to logs.
+func (v SecretVersion) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Uint64("version_id", uint64(v.VersionID)),
+		slog.String("created_at", v.CreatedAt),
+		slog.String("value", redacted),
+	)
+}
+
+// LogValue implements slog.LogValuer, so the value of the secret never gets to logs.
+func (us UserSecret) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("key", us.Key),
+		slog.String("description", us.Description),
+		slog.String("value", redacted),
+	)
 }
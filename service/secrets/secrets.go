@@ -3,28 +3,80 @@ package secrets
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 
+	"github.com/selectel/secretsmanager-go/internal/cache"
 	"github.com/selectel/secretsmanager-go/internal/httpclient"
 	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
 )
 
 const apiVersion = "v1"
 
+// Names of operations, that are passed to middlewares.
+const (
+	OperationList   = "secrets.List"
+	OperationDelete = "secrets.Delete"
+	OperationGet    = "secrets.Get"
+	OperationUpdate = "secrets.Update"
+	OperationCreate = "secrets.Create"
+
+	OperationListVersions = "secrets.ListVersions"
+	OperationGetVersion   = "secrets.GetVersion"
+
+	// Operations, that make several requests, are passed to middleware.OperationObserver.
+	OperationRollback        = "secrets.Rollback"
+	OperationUpdateIfVersion = "secrets.UpdateIfVersion"
+	OperationUpdateFunc      = "secrets.UpdateFunc"
+	OperationPut             = "secrets.Put"
+	OperationGetMany         = "secrets.GetMany"
+	OperationCreateMany      = "secrets.CreateMany"
+	OperationDeleteMany      = "secrets.DeleteMany"
+	OperationListPrefix      = "secrets.ListPrefix"
+	OperationListChildren    = "secrets.ListChildren"
+	OperationDeletePrefix    = "secrets.DeletePrefix"
+	OperationLoad            = "secrets.Load"
+)
+
 // Service implements Secrets Manager part that is responsible for handling secrets operations.
 type Service struct {
 	apiURLSecrets string
 	httpClient    *httpclient.HTTPClient
+	cache         *cache.Cache[Secret]
+}
+
+// Option is a functional parameter for New.
+type Option func(*Service)
+
+// WithCache caches secrets returned by Get, see cache.Options for lifetimes of entries.
+func WithCache(opts cache.Options) Option {
+	return func(s *Service) {
+		s.cache = cache.New[Secret](opts)
+	}
 }
 
-func New(url string, client *httpclient.HTTPClient) *Service {
-	return &Service{
+func New(url string, client *httpclient.HTTPClient, opts ...Option) *Service {
+	s := &Service{
 		apiURLSecrets: url,
 		httpClient:    client,
 	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// invalidate drops the cached secret after a write, the write may have been applied even if it has failed.
+func (s Service) invalidate(key string) {
+	if s.cache != nil {
+		s.cache.Invalidate(key)
+	}
 }
 
 func (s Service) List(ctx context.Context) (Secrets, error) {
@@ -48,7 +100,7 @@ func (s Service) List(ctx context.Context) (Secrets, error) {
 	q.Add("list", "")
 	endpoint.RawQuery = q.Encode()
 
-	respBody, err := s.httpClient.DoRequest(ctx, http.MethodGet, endpoint.String(), nil)
+	respBody, err := s.httpClient.DoRequest(ctx, OperationList, "", http.MethodGet, endpoint.String(), nil)
 	if err != nil {
 		return Secrets{}, err //nolint:wrapcheck // DoRequest already wraps the error.
 	}
@@ -72,14 +124,12 @@ func (s Service) Delete(ctx context.Context, key string) error {
 		}
 	}
 
-	endpoint, err := url.JoinPath(s.apiURLSecrets, apiVersion, key)
+	endpoint, err := s.secretEndpoint(key)
 	if err != nil {
-		return secretsmanagererrors.Error{
-			Err:  secretsmanagererrors.ErrInternalAppError,
-			Desc: err.Error(),
-		}
+		return err
 	}
-	_, err = s.httpClient.DoRequest(ctx, http.MethodDelete, endpoint, nil)
+	_, err = s.httpClient.DoRequest(ctx, OperationDelete, key, http.MethodDelete, endpoint, nil)
+	s.invalidate(key)
 	if err != nil {
 		return err //nolint:wrapcheck // DoRequest already wraps the error.
 	}
@@ -87,7 +137,24 @@ func (s Service) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// Get returns the secret with a value of its latest version.
+// If the service is created with WithCache, the secret may be returned from the cache.
 func (s Service) Get(ctx context.Context, key string) (Secret, error) {
+	if s.cache == nil || len(key) == 0 {
+		return s.get(ctx, key)
+	}
+
+	sc, err := s.cache.Get(ctx, key, func(ctx context.Context) (Secret, error) {
+		return s.get(ctx, key)
+	})
+	// The value is copied, so callers can't modify the cached one.
+	sc.Version.Value = bytes.Clone(sc.Version.Value)
+
+	return sc, err //nolint:wrapcheck // get already wraps the error.
+}
+
+// get requests the secret bypassing the cache.
+func (s Service) get(ctx context.Context, key string) (Secret, error) {
 	if len(key) == 0 {
 		return Secret{}, secretsmanagererrors.Error{
 			Err:  secretsmanagererrors.ErrEmptySecretName,
@@ -95,15 +162,12 @@ func (s Service) Get(ctx context.Context, key string) (Secret, error) {
 		}
 	}
 
-	endpoint, err := url.JoinPath(s.apiURLSecrets, apiVersion, key)
+	endpoint, err := s.secretEndpoint(key)
 	if err != nil {
-		return Secret{}, secretsmanagererrors.Error{
-			Err:  secretsmanagererrors.ErrCannotFormatEndpoint,
-			Desc: err.Error(),
-		}
+		return Secret{}, err
 	}
 
-	respBody, err := s.httpClient.DoRequest(ctx, http.MethodGet, endpoint, nil)
+	respBody, err := s.httpClient.DoRequest(ctx, OperationGet, key, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return Secret{}, err //nolint:wrapcheck // DoRequest already wraps the error.
 	}
@@ -128,12 +192,9 @@ func (s Service) Update(ctx context.Context, usc UserSecret) error {
 		}
 	}
 
-	endpoint, err := url.JoinPath(s.apiURLSecrets, apiVersion, usc.Key)
+	endpoint, err := s.secretEndpoint(usc.Key)
 	if err != nil {
-		return secretsmanagererrors.Error{
-			Err:  secretsmanagererrors.ErrCannotFormatEndpoint,
-			Desc: err.Error(),
-		}
+		return err
 	}
 
 	marshalled, err := json.Marshal(usc)
@@ -146,7 +207,8 @@ func (s Service) Update(ctx context.Context, usc UserSecret) error {
 
 	reqBody := bytes.NewReader(marshalled)
 
-	_, err = s.httpClient.DoRequest(ctx, http.MethodPut, endpoint, reqBody)
+	_, err = s.httpClient.DoRequest(ctx, OperationUpdate, usc.Key, http.MethodPut, endpoint, reqBody)
+	s.invalidate(usc.Key)
 	if err != nil {
 		return err //nolint:wrapcheck // DoRequest already wraps the error.
 	}
@@ -168,14 +230,10 @@ func (s Service) Create(ctx context.Context, usc UserSecret) error {
 			Desc: "field value in secret is empty",
 		}
 	}
-	usc.Value = base64.StdEncoding.EncodeToString([]byte(usc.Value))
 
-	endpoint, err := url.JoinPath(s.apiURLSecrets, apiVersion, usc.Key)
+	endpoint, err := s.secretEndpoint(usc.Key)
 	if err != nil {
-		return secretsmanagererrors.Error{
-			Err:  secretsmanagererrors.ErrCannotFormatEndpoint,
-			Desc: err.Error(),
-		}
+		return err
 	}
 
 	marshalled, err := json.Marshal(usc)
@@ -187,10 +245,250 @@ func (s Service) Create(ctx context.Context, usc UserSecret) error {
 	}
 
 	reqBody := bytes.NewReader(marshalled)
-	_, err = s.httpClient.DoRequest(ctx, http.MethodPost, endpoint, reqBody)
+	_, err = s.httpClient.DoRequest(ctx, OperationCreate, usc.Key, http.MethodPost, endpoint, reqBody)
+	s.invalidate(usc.Key)
 	if err != nil {
 		return err //nolint:wrapcheck // DoRequest already wraps the error.
 	}
 
 	return nil
 }
+
+// ListVersions returns all versions of the secret with their creation times, values are not included.
+func (s Service) ListVersions(ctx context.Context, key string) (Versions, error) {
+	if len(key) == 0 {
+		return Versions{}, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrEmptySecretName,
+			Desc: "field name in secret is empty",
+		}
+	}
+
+	endpoint, err := s.secretEndpoint(key, "versions")
+	if err != nil {
+		return Versions{}, err
+	}
+
+	respBody, err := s.httpClient.DoRequest(ctx, OperationListVersions, key, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Versions{}, err //nolint:wrapcheck // DoRequest already wraps the error.
+	}
+
+	var vs Versions
+	err = json.Unmarshal(respBody, &vs)
+	if err != nil {
+		return Versions{}, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrCannotUnmarshalBody,
+			Desc: err.Error(),
+		}
+	}
+
+	return vs, nil
+}
+
+// GetVersion returns the given version of the secret, which is not necessarily the latest one.
+func (s Service) GetVersion(ctx context.Context, key string, versionID uint) (SecretVersion, error) {
+	if len(key) == 0 {
+		return SecretVersion{}, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrEmptySecretName,
+			Desc: "field name in secret is empty",
+		}
+	}
+
+	version := strconv.FormatUint(uint64(versionID), 10)
+	endpoint, err := s.secretEndpoint(key, "versions", version)
+	if err != nil {
+		return SecretVersion{}, err
+	}
+
+	respBody, err := s.httpClient.DoRequest(ctx, OperationGetVersion, key, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return SecretVersion{}, err //nolint:wrapcheck // DoRequest already wraps the error.
+	}
+
+	var sv SecretVersion
+	err = json.Unmarshal(respBody, &sv)
+	if err != nil {
+		return SecretVersion{}, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrCannotUnmarshalBody,
+			Desc: err.Error(),
+		}
+	}
+
+	return sv, nil
+}
+
+// Rollback writes a value of an earlier version of the secret as a new current version
+// and returns the secret after verifying, that its latest version has the rolled back value.
+// If the secret has been changed concurrently after the write, ErrSecretOverwritten is returned.
+func (s Service) Rollback(ctx context.Context, key string, versionID uint) (_ Secret, err error) {
+	ctx, end := s.httpClient.StartOperation(ctx, OperationRollback, key)
+	defer func() { end(err) }()
+
+	version, err := s.GetVersion(ctx, key, versionID)
+	if err != nil {
+		return Secret{}, err
+	}
+
+	if len(version.Value) == 0 {
+		return Secret{}, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrEmptySecretValue,
+			Desc: fmt.Sprintf("version %d of secret %s has no value", versionID, key),
+		}
+	}
+
+	err = s.Update(ctx, UserSecret{Key: key, Value: version.Value})
+	if err != nil {
+		return Secret{}, err
+	}
+
+	sc, err := s.get(ctx, key)
+	if err != nil {
+		return Secret{}, err
+	}
+
+	if !bytes.Equal(sc.Version.Value, version.Value) {
+		return Secret{}, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrSecretOverwritten,
+			Desc: fmt.Sprintf("secret %s has been changed during rollback to version %d", key, versionID),
+		}
+	}
+
+	return sc, nil
+}
+
+// UpdateIfVersion updates the secret only if its latest version is still expectedVersionID.
+// It is not an atomic compare-and-swap: the version is checked by the client, then the secret is updated,
+// then the written value is verified. ErrConflictStatusText is returned, if the version has already moved on,
+// so nothing has been written. ErrSecretOverwritten is returned, if the value has been written
+// and then overwritten by someone else, so the update has landed and must not be simply repeated.
+// Updates, that change only the description (empty Value), are never verified.
+func (s Service) UpdateIfVersion(ctx context.Context, usc UserSecret, expectedVersionID uint) (err error) {
+	ctx, end := s.httpClient.StartOperation(ctx, OperationUpdateIfVersion, usc.Key)
+	defer func() { end(err) }()
+
+	current, err := s.get(ctx, usc.Key)
+	if err != nil {
+		return err
+	}
+
+	if current.Version.VersionID != expectedVersionID {
+		return secretsmanagererrors.Error{
+			Err: secretsmanagererrors.ErrConflictStatusText,
+			Desc: fmt.Sprintf("secret %s is at version %d, expected %d",
+				usc.Key, current.Version.VersionID, expectedVersionID),
+		}
+	}
+
+	err = s.Update(ctx, usc)
+	if err != nil {
+		return err
+	}
+
+	if len(usc.Value) == 0 {
+		return nil
+	}
+
+	updated, err := s.get(ctx, usc.Key)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(updated.Version.Value, usc.Value) {
+		return secretsmanagererrors.Error{
+			Err: secretsmanagererrors.ErrSecretOverwritten,
+			Desc: fmt.Sprintf("the value of secret %s has been written, but has been overwritten concurrently "+
+				"after version %d", usc.Key, expectedVersionID),
+		}
+	}
+
+	return nil
+}
+
+// maxUpdateAttempts represents how many times UpdateFunc re-reads the secret after a conflict.
+const maxUpdateAttempts = 5
+
+// UpdateFunc reads the secret, passes it to mutate and writes the result with UpdateIfVersion.
+// If the secret has been changed concurrently before the write, it is re-read and mutate is called again,
+// so mutate has to be free of side effects. If the value has been written and then overwritten,
+// ErrSecretOverwritten is returned without calling mutate again. An error returned by mutate is returned as is.
+func (s Service) UpdateFunc(
+	ctx context.Context, key string, mutate func(current Secret) (UserSecret, error),
+) (err error) {
+	ctx, end := s.httpClient.StartOperation(ctx, OperationUpdateFunc, key)
+	defer func() { end(err) }()
+
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		var current Secret
+		current, err = s.get(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		var usc UserSecret
+		usc, err = mutate(current)
+		if err != nil {
+			return err
+		}
+		usc.Key = key
+
+		err = s.UpdateIfVersion(ctx, usc, current.Version.VersionID)
+		if !errors.Is(err, secretsmanagererrors.ErrConflictStatusText) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// PutResult — an outcome of Put.
+type PutResult int
+
+const (
+	// PutCreatedSecret means, that the secret didn't exist and has been created.
+	PutCreatedSecret PutResult = iota + 1
+	// PutCreatedVersion means, that the secret existed and a new version of it has been created.
+	PutCreatedVersion
+)
+
+func (r PutResult) String() string {
+	switch r {
+	case PutCreatedSecret:
+		return "created secret"
+	case PutCreatedVersion:
+		return "created version"
+	default:
+		return "unknown"
+	}
+}
+
+// maxPutAttempts represents how many times Put switches between Create and Update,
+// while the secret is being created and deleted concurrently.
+const maxPutAttempts = 3
+
+// Put creates the secret, if it doesn't exist, or creates a new version of it otherwise,
+// the value is encoded the same way in both cases. If another writer creates or deletes
+// the secret concurrently, Put switches to Update or Create respectively.
+func (s Service) Put(ctx context.Context, usc UserSecret) (_ PutResult, err error) {
+	ctx, end := s.httpClient.StartOperation(ctx, OperationPut, usc.Key)
+	defer func() { end(err) }()
+
+	for attempt := 0; attempt < maxPutAttempts; attempt++ {
+		err = s.Create(ctx, usc)
+		if err == nil {
+			return PutCreatedSecret, nil
+		}
+		if !errors.Is(err, secretsmanagererrors.ErrConflictStatusText) {
+			return 0, err
+		}
+
+		err = s.Update(ctx, usc)
+		if err == nil {
+			return PutCreatedVersion, nil
+		}
+		if !errors.Is(err, secretsmanagererrors.ErrNotFoundStatusText) {
+			return 0, err
+		}
+	}
+
+	return 0, err
+}
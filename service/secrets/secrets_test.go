@@ -2,6 +2,8 @@ package secrets_test
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"net/http"
 	"testing"
 	"time"
@@ -10,6 +12,7 @@ import (
 	"github.com/stretchr/testify/suite"
 
 	"github.com/selectel/secretsmanager-go/internal/auth"
+	"github.com/selectel/secretsmanager-go/internal/cache"
 	"github.com/selectel/secretsmanager-go/internal/httpclient"
 	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
 	"github.com/selectel/secretsmanager-go/service/secrets"
@@ -132,9 +135,10 @@ func (suite *SecretsSuite) TestGet() {
 				Description: "dummy-description",
 				Name:        testDummyKey,
 				Version: secrets.SecretVersion{
-					CreatedAt: "2023-12-26T09:48:01Z",
-					Value:     "dmFsdWU=",
-					VersionID: 0,
+					CreatedAt:   "2023-12-26T09:48:01Z",
+					Value:       []byte("value"),
+					VersionID:   0,
+					StoredValue: "dmFsdWU=",
 				},
 			},
 			http.StatusOK,
@@ -229,7 +233,7 @@ func (suite *SecretsSuite) TestCreate() {
 			secrets.UserSecret{
 				Key:         testDummyKey,
 				Description: "dummy-description",
-				Value:       "dmFsdWU=",
+				Value:       []byte("value"),
 			},
 			http.StatusOK,
 			nil,
@@ -238,7 +242,7 @@ func (suite *SecretsSuite) TestCreate() {
 			testDummyKey,
 			secrets.UserSecret{
 				Key:   testDummyKey,
-				Value: "dmFsdWU=",
+				Value: []byte("value"),
 			},
 			http.StatusOK,
 			nil,
@@ -247,7 +251,7 @@ func (suite *SecretsSuite) TestCreate() {
 			"",
 			secrets.UserSecret{
 				Key:   "",
-				Value: "dmFsdWU=",
+				Value: []byte("value"),
 			},
 			http.StatusInternalServerError,
 			secretsmanagererrors.ErrEmptySecretName,
@@ -256,7 +260,7 @@ func (suite *SecretsSuite) TestCreate() {
 			testDummyKey,
 			secrets.UserSecret{
 				Key:   testDummyKey,
-				Value: "",
+				Value: nil,
 			},
 			http.StatusInternalServerError,
 			secretsmanagererrors.ErrEmptySecretValue,
@@ -280,3 +284,674 @@ func (suite *SecretsSuite) TestCreate() {
 		})
 	}
 }
+
+func (suite *SecretsSuite) TestBinaryValueRoundTrip() {
+	value := make([]byte, 256)
+	for i := range value {
+		value[i] = byte(i)
+	}
+	encoded := base64.StdEncoding.EncodeToString(value)
+
+	gock.New(testDummyEndpoint).
+		Post(testDummyKey).
+		MatchType("json").
+		JSON(map[string]string{"value": encoded}).
+		Reply(http.StatusOK)
+
+	gock.New(testDummyEndpoint).
+		Put(testDummyKey).
+		MatchType("json").
+		JSON(map[string]string{"value": encoded}).
+		Reply(http.StatusOK)
+
+	gock.New(testDummyEndpoint).
+		Get(testDummyKey).
+		Reply(http.StatusOK).
+		JSON(map[string]any{"name": testDummyKey, "version": map[string]any{"value": encoded}})
+
+	ctx := context.Background()
+	usc := secrets.UserSecret{Key: testDummyKey, Value: value}
+	suite.Require().NoError(suite.service.Create(ctx, usc))
+	suite.Require().NoError(suite.service.Update(ctx, usc))
+
+	got, err := suite.service.Get(ctx, testDummyKey)
+	suite.Require().NoError(err)
+	suite.Equal(value, got.Version.Value)
+	suite.Equal(encoded, got.Version.Base64Value())
+}
+
+func (suite *SecretsSuite) TestGetRawValue() {
+	gock.New(testDummyEndpoint).
+		Get("/v1/" + testDummyKey).
+		Reply(http.StatusOK).
+		JSON(map[string]any{"name": testDummyKey, "version": map[string]any{"value": "not base64!", "version_id": 2}})
+
+	sc, err := suite.service.Get(context.Background(), testDummyKey)
+	suite.Require().NoError(err)
+	suite.Equal([]byte("not base64!"), sc.Version.Value)
+	suite.True(sc.Version.RawValue)
+	suite.Equal("not base64!", sc.Version.Base64Value())
+	suite.EqualValues(2, sc.Version.VersionID)
+}
+
+func (suite *SecretsSuite) TestGetRawValueValidBase64() {
+	gock.New(testDummyEndpoint).
+		Get("/v1/" + testDummyKey).
+		Reply(http.StatusOK).
+		JSON(map[string]any{"name": testDummyKey, "version": map[string]any{"value": "password", "version_id": 2}})
+
+	sc, err := suite.service.Get(context.Background(), testDummyKey)
+	suite.Require().NoError(err)
+	suite.False(sc.Version.RawValue)
+	suite.Equal("password", sc.Version.StoredValue)
+	suite.Equal("password", sc.Version.Base64Value())
+}
+
+func (suite *SecretsSuite) TestValueFromBase64() {
+	value, err := secrets.ValueFromBase64("dmFsdWU=")
+	suite.Require().NoError(err)
+	suite.Equal([]byte("value"), value)
+
+	_, err = secrets.ValueFromBase64("not base64!")
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrInvalidSecretValue)
+}
+
+func (suite *SecretsSuite) TestListVersions() {
+	expectedVersions := secrets.Versions{
+		Versions: []secrets.VersionMetadata{
+			{CreatedAt: "2023-12-26T09:48:01Z", VersionID: 0},
+			{CreatedAt: "2024-01-15T12:00:00Z", VersionID: 1},
+		},
+	}
+
+	gock.New(testDummyEndpoint).
+		Get(testDummyKey + "/versions").
+		Reply(http.StatusOK).
+		File("./fixtures/versions-response-data.json")
+
+	ctx := context.Background()
+	res, err := suite.service.ListVersions(ctx, testDummyKey)
+	suite.Require().NoError(err)
+	suite.Equal(expectedVersions, res)
+
+	_, err = suite.service.ListVersions(ctx, "")
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrEmptySecretName)
+}
+
+func (suite *SecretsSuite) TestGetVersion() {
+	tests := map[string]struct {
+		key        string
+		expVersion secrets.SecretVersion
+		expErr     error
+	}{
+		"Successful Get": {
+			testDummyKey,
+			secrets.SecretVersion{
+				CreatedAt:   "2023-12-26T09:48:01Z",
+				Value:       []byte("value"),
+				VersionID:   0,
+				StoredValue: "dmFsdWU=",
+			},
+			nil,
+		},
+		"Empty Key": {
+			"",
+			secrets.SecretVersion{},
+			secretsmanagererrors.ErrEmptySecretName,
+		},
+	}
+
+	gock.New(testDummyEndpoint).
+		Get(testDummyKey + "/versions/0").
+		Reply(http.StatusOK).
+		File("./fixtures/version-response-data.json")
+
+	for name, test := range tests {
+		suite.T().Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			got, err := suite.service.GetVersion(ctx, test.key, 0)
+
+			suite.Require().ErrorIs(err, test.expErr)
+			suite.Equal(test.expVersion, got)
+		})
+	}
+}
+
+func (suite *SecretsSuite) TestRollback() {
+	gock.New(testDummyEndpoint).
+		Get(testDummyKey + "/versions/0").
+		Reply(http.StatusOK).
+		File("./fixtures/version-response-data.json")
+
+	gock.New(testDummyEndpoint).
+		Put(testDummyKey).
+		MatchType("json").
+		JSON(map[string]string{"value": "dmFsdWU="}).
+		Reply(http.StatusOK)
+
+	gock.New(testDummyEndpoint).
+		Get(testDummyKey).
+		Reply(http.StatusOK).
+		JSON(map[string]any{"name": testDummyKey, "version": map[string]any{"value": "dmFsdWU=", "version_id": 2}})
+
+	got, err := suite.service.Rollback(context.Background(), testDummyKey, 0)
+	suite.Require().NoError(err)
+	suite.Equal([]byte("value"), got.Version.Value)
+	suite.Equal(uint(2), got.Version.VersionID)
+}
+
+func (suite *SecretsSuite) TestRollbackConflict() {
+	gock.New(testDummyEndpoint).
+		Get(testDummyKey + "/versions/0").
+		Reply(http.StatusOK).
+		File("./fixtures/version-response-data.json")
+
+	gock.New(testDummyEndpoint).
+		Put(testDummyKey).
+		Reply(http.StatusOK)
+
+	gock.New(testDummyEndpoint).
+		Get(testDummyKey).
+		Reply(http.StatusOK).
+		JSON(map[string]any{"name": testDummyKey, "version": map[string]any{"value": "b3RoZXI=", "version_id": 3}})
+
+	_, err := suite.service.Rollback(context.Background(), testDummyKey, 0)
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrSecretOverwritten)
+	suite.NotContains(err.Error(), "other")
+}
+
+func mockGetVersion(value string, versionID uint) {
+	gock.New(testDummyEndpoint).
+		Get(testDummyKey).
+		Reply(http.StatusOK).
+		JSON(map[string]any{"name": testDummyKey, "version": map[string]any{"value": value, "version_id": versionID}})
+}
+
+func (suite *SecretsSuite) TestUpdateIfVersion() {
+	mockGetVersion("b2xk", 1)
+	gock.New(testDummyEndpoint).
+		Put(testDummyKey).
+		MatchType("json").
+		JSON(map[string]string{"value": "bmV3"}).
+		Reply(http.StatusOK)
+	mockGetVersion("bmV3", 2)
+
+	usc := secrets.UserSecret{Key: testDummyKey, Value: []byte("new")}
+	err := suite.service.UpdateIfVersion(context.Background(), usc, 1)
+	suite.Require().NoError(err)
+}
+
+func (suite *SecretsSuite) TestUpdateIfVersionConflict() {
+	mockGetVersion("b2xk", 2)
+
+	usc := secrets.UserSecret{Key: testDummyKey, Value: []byte("new")}
+	err := suite.service.UpdateIfVersion(context.Background(), usc, 1)
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrConflictStatusText)
+	suite.Require().ErrorContains(err, "is at version 2, expected 1")
+}
+
+func (suite *SecretsSuite) TestUpdateIfVersionOverwritten() {
+	mockGetVersion("b2xk", 1)
+	gock.New(testDummyEndpoint).
+		Put(testDummyKey).
+		Reply(http.StatusOK)
+	mockGetVersion("b3RoZXI=", 3)
+
+	usc := secrets.UserSecret{Key: testDummyKey, Value: []byte("new")}
+	err := suite.service.UpdateIfVersion(context.Background(), usc, 1)
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrSecretOverwritten)
+	suite.Require().NotErrorIs(err, secretsmanagererrors.ErrConflictStatusText)
+	suite.Require().ErrorContains(err, "has been written, but has been overwritten")
+}
+
+func (suite *SecretsSuite) TestUpdateFunc() {
+	// The first attempt conflicts with a concurrent update.
+	mockGetVersion("MQ==", 1)
+	mockGetVersion("Mg==", 2)
+	// The second attempt applies cleanly.
+	mockGetVersion("Mg==", 2)
+	mockGetVersion("Mg==", 2)
+	gock.New(testDummyEndpoint).
+		Put(testDummyKey).
+		MatchType("json").
+		JSON(map[string]string{"value": "Mis="}).
+		Reply(http.StatusOK)
+	mockGetVersion("Mis=", 3)
+
+	var calls int
+	mutate := func(current secrets.Secret) (secrets.UserSecret, error) {
+		calls++
+		return secrets.UserSecret{Value: append(current.Version.Value, '+')}, nil
+	}
+	err := suite.service.UpdateFunc(context.Background(), testDummyKey, mutate)
+	suite.Require().NoError(err)
+	suite.Equal(2, calls)
+}
+
+func (suite *SecretsSuite) TestUpdateFuncOverwritten() {
+	mockGetVersion("MQ==", 1)
+	mockGetVersion("MQ==", 1)
+	gock.New(testDummyEndpoint).
+		Put(testDummyKey).
+		Reply(http.StatusOK)
+	mockGetVersion("b3RoZXI=", 3)
+
+	var calls int
+	mutate := func(current secrets.Secret) (secrets.UserSecret, error) {
+		calls++
+		return secrets.UserSecret{Value: append(current.Version.Value, '+')}, nil
+	}
+	err := suite.service.UpdateFunc(context.Background(), testDummyKey, mutate)
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrSecretOverwritten)
+	suite.Equal(1, calls)
+}
+
+func (suite *SecretsSuite) TestUpdateFuncMutateError() {
+	mockGetVersion("MQ==", 1)
+
+	errMutate := errors.New("mutate failed")
+	mutate := func(secrets.Secret) (secrets.UserSecret, error) {
+		return secrets.UserSecret{}, errMutate
+	}
+	err := suite.service.UpdateFunc(context.Background(), testDummyKey, mutate)
+	suite.Require().ErrorIs(err, errMutate)
+}
+
+func (suite *SecretsSuite) TestPut() {
+	tests := map[string]struct {
+		mock      func()
+		expResult secrets.PutResult
+		expErr    error
+	}{
+		"New Secret": {
+			func() {
+				gock.New(testDummyEndpoint).
+					Post(testDummyKey).
+					MatchType("json").
+					JSON(map[string]string{"value": "dmFsdWU="}).
+					Reply(http.StatusOK)
+			},
+			secrets.PutCreatedSecret,
+			nil,
+		},
+		"Existing Secret": {
+			func() {
+				gock.New(testDummyEndpoint).
+					Post(testDummyKey).
+					Reply(http.StatusConflict).
+					JSON(map[string]string{"status_text": "CONFLICT"})
+				gock.New(testDummyEndpoint).
+					Put(testDummyKey).
+					MatchType("json").
+					JSON(map[string]string{"value": "dmFsdWU="}).
+					Reply(http.StatusOK)
+			},
+			secrets.PutCreatedVersion,
+			nil,
+		},
+		"Deleted Concurrently": {
+			func() {
+				gock.New(testDummyEndpoint).
+					Post(testDummyKey).
+					Reply(http.StatusConflict).
+					JSON(map[string]string{"status_text": "CONFLICT"})
+				gock.New(testDummyEndpoint).
+					Put(testDummyKey).
+					Reply(http.StatusNotFound).
+					JSON(map[string]string{"status_text": "NOT_FOUND"})
+				gock.New(testDummyEndpoint).
+					Post(testDummyKey).
+					Reply(http.StatusOK)
+			},
+			secrets.PutCreatedSecret,
+			nil,
+		},
+		"Forbidden": {
+			func() {
+				gock.New(testDummyEndpoint).
+					Post(testDummyKey).
+					Reply(http.StatusForbidden).
+					JSON(map[string]string{"status_text": "FORBIDDEN"})
+			},
+			0,
+			secretsmanagererrors.ErrForbiddenStatusText,
+		},
+	}
+
+	for name, test := range tests {
+		suite.T().Run(name, func(t *testing.T) {
+			test.mock()
+
+			usc := secrets.UserSecret{Key: testDummyKey, Value: []byte("value")}
+			got, err := suite.service.Put(context.Background(), usc)
+
+			suite.Require().ErrorIs(err, test.expErr)
+			suite.Equal(test.expResult, got)
+			suite.Require().True(gock.IsDone())
+		})
+	}
+}
+
+func mockListHierarchical() {
+	gock.New(testDummyEndpoint+"?").
+		Get("").
+		MatchParam("list", "").
+		Reply(http.StatusOK).
+		File("./fixtures/hierarchical-secrets-response-data.json")
+}
+
+func (suite *SecretsSuite) TestHierarchicalKeyEscaping() {
+	gock.New(testDummyEndpoint).
+		Delete("/v1/payments/prod/").
+		AddMatcher(func(req *http.Request, _ *gock.Request) (bool, error) {
+			return req.URL.EscapedPath() == "/v1/payments/prod/a%20b%3Fc%25d", nil
+		}).
+		Reply(http.StatusNoContent)
+
+	ctx := context.Background()
+	err := suite.service.Delete(ctx, "payments/prod/a b?c%d")
+	suite.Require().NoError(err)
+
+	for _, key := range []string{"/payments", "payments/", "payments//db", "payments/../admin", "./payments"} {
+		err = suite.service.Delete(ctx, key)
+		suite.Require().ErrorIs(err, secretsmanagererrors.ErrInvalidSecretName, key)
+	}
+}
+
+func (suite *SecretsSuite) TestListPrefix() {
+	mockListHierarchical()
+
+	sc, err := suite.service.ListPrefix(context.Background(), "payments/prod/")
+	suite.Require().NoError(err)
+
+	var names []string
+	for _, key := range sc.Keys {
+		names = append(names, key.Name)
+	}
+	suite.Equal([]string{
+		"payments/prod/db/password",
+		"payments/prod/db/user",
+		"payments/prod/token",
+		"payments/prod",
+	}, names)
+}
+
+func (suite *SecretsSuite) TestListChildren() {
+	mockListHierarchical()
+	mockListHierarchical()
+
+	ctx := context.Background()
+	children, err := suite.service.ListChildren(ctx, "payments/prod")
+	suite.Require().NoError(err)
+	suite.Equal([]string{"db/", "token"}, children)
+
+	children, err = suite.service.ListChildren(ctx, "")
+	suite.Require().NoError(err)
+	suite.Equal([]string{"payments/"}, children)
+}
+
+func (suite *SecretsSuite) TestDeletePrefix() {
+	mockListHierarchical()
+	for _, key := range []string{"payments/prod/db/password", "payments/prod/db/user"} {
+		gock.New(testDummyEndpoint).
+			Delete("/v1/" + key).
+			Reply(http.StatusNoContent)
+	}
+
+	ctx := context.Background()
+	deleted, err := suite.service.DeletePrefix(ctx, "payments/prod/db")
+	suite.Require().NoError(err)
+	suite.Equal([]string{"payments/prod/db/password", "payments/prod/db/user"}, deleted)
+
+	_, err = suite.service.DeletePrefix(ctx, "/")
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrEmptySecretName)
+}
+
+func (suite *SecretsSuite) TestAll() {
+	since := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		opts     secrets.ListOptions
+		expNames []string
+	}{
+		"API Order":       {secrets.ListOptions{}, []string{"Bla", "IAM"}},
+		"Sort Created At": {secrets.ListOptions{SortBy: secrets.SortByCreatedAt}, []string{"IAM", "Bla"}},
+		"Sort Descending": {secrets.ListOptions{SortBy: secrets.SortByName, Descending: true}, []string{"IAM", "Bla"}},
+		"Prefix":          {secrets.ListOptions{Prefix: "IA"}, []string{"IAM"}},
+		"Type":            {secrets.ListOptions{Type: "Certificate"}, nil},
+		"Created After":   {secrets.ListOptions{CreatedAfter: since}, []string{"Bla"}},
+	}
+
+	for name, test := range tests {
+		suite.T().Run(name, func(t *testing.T) {
+			gock.New(testDummyEndpoint+"?").
+				Get("").
+				MatchParam("list", "").
+				Reply(http.StatusOK).
+				File("./fixtures/secrets-response-data.json")
+
+			var names []string
+			for key, err := range suite.service.All(context.Background(), test.opts) {
+				suite.Require().NoError(err)
+				names = append(names, key.Name)
+			}
+
+			suite.Equal(test.expNames, names)
+		})
+	}
+}
+
+func (suite *SecretsSuite) TestAllError() {
+	gock.New(testDummyEndpoint+"?").
+		Get("").
+		MatchParam("list", "").
+		Reply(http.StatusForbidden).
+		JSON(map[string]string{"status_text": "FORBIDDEN"})
+
+	var errs []error
+	for _, err := range suite.service.All(context.Background(), secrets.ListOptions{}) {
+		errs = append(errs, err)
+	}
+
+	suite.Require().Len(errs, 1)
+	suite.Require().ErrorIs(errs[0], secretsmanagererrors.ErrForbiddenStatusText)
+}
+
+func (suite *SecretsSuite) TestGetMany() {
+	gock.New(testDummyEndpoint).
+		Get("/v1/first").
+		Reply(http.StatusOK).
+		JSON(map[string]any{"name": "first", "version": map[string]any{"value": "MQ=="}})
+	gock.New(testDummyEndpoint).
+		Get("/v1/second").
+		Reply(http.StatusNotFound).
+		JSON(map[string]string{"status_text": "NOT_FOUND"})
+
+	keys := []string{"first", "second"}
+	results, err := suite.service.GetMany(context.Background(), keys, secrets.BulkOptions{Workers: 2})
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrNotFoundStatusText)
+	suite.Require().ErrorContains(err, "second: ")
+	suite.Require().Len(results, 2)
+
+	suite.Equal("first", results[0].Key)
+	suite.NoError(results[0].Err)
+	suite.Equal([]byte("1"), results[0].Secret.Version.Value)
+
+	suite.Equal("second", results[1].Key)
+	suite.ErrorIs(results[1].Err, secretsmanagererrors.ErrNotFoundStatusText)
+}
+
+func (suite *SecretsSuite) TestCreateManyAtomic() {
+	gock.New(testDummyEndpoint).
+		Post("/v1/first").
+		Reply(http.StatusOK)
+	gock.New(testDummyEndpoint).
+		Post("/v1/second").
+		Reply(http.StatusConflict).
+		JSON(map[string]string{"status_text": "CONFLICT"})
+	gock.New(testDummyEndpoint).
+		Delete("/v1/first").
+		Reply(http.StatusNoContent)
+
+	uscs := []secrets.UserSecret{
+		{Key: "first", Value: []byte("1")},
+		{Key: "second", Value: []byte("2")},
+	}
+	results, err := suite.service.CreateMany(context.Background(), uscs, secrets.BulkOptions{Atomic: true})
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrConflictStatusText)
+
+	suite.Equal(secrets.ItemResult{Key: "first", RolledBack: true}, results[0])
+	suite.Require().ErrorIs(results[1].Err, secretsmanagererrors.ErrConflictStatusText)
+	suite.False(results[1].RolledBack)
+}
+
+func (suite *SecretsSuite) TestDeleteManyAtomic() {
+	gock.New(testDummyEndpoint).
+		Get("/v1/first").
+		Reply(http.StatusOK).
+		JSON(map[string]any{"name": "first", "description": "one", "version": map[string]any{"value": "MQ=="}})
+	gock.New(testDummyEndpoint).
+		Get("/v1/second").
+		Reply(http.StatusOK).
+		JSON(map[string]any{"name": "second", "version": map[string]any{"value": "Mg=="}})
+	gock.New(testDummyEndpoint).
+		Delete("/v1/first").
+		Reply(http.StatusNoContent)
+	gock.New(testDummyEndpoint).
+		Delete("/v1/second").
+		Reply(http.StatusForbidden).
+		JSON(map[string]string{"status_text": "FORBIDDEN"})
+	gock.New(testDummyEndpoint).
+		Post("/v1/first").
+		MatchType("json").
+		JSON(map[string]string{"description": "one", "value": "MQ=="}).
+		Reply(http.StatusOK)
+
+	keys := []string{"first", "second"}
+	results, err := suite.service.DeleteMany(context.Background(), keys, secrets.BulkOptions{Atomic: true})
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrForbiddenStatusText)
+
+	suite.Equal(secrets.ItemResult{Key: "first", RolledBack: true}, results[0])
+	suite.Require().ErrorIs(results[1].Err, secretsmanagererrors.ErrForbiddenStatusText)
+}
+
+func (suite *SecretsSuite) TestDeleteManyAtomicUnreadable() {
+	gock.New(testDummyEndpoint).
+		Get("/v1/first").
+		Reply(http.StatusNotFound).
+		JSON(map[string]string{"status_text": "NOT_FOUND"})
+	gock.New(testDummyEndpoint).
+		Get("/v1/second").
+		Reply(http.StatusOK).
+		JSON(map[string]any{"name": "second", "version": map[string]any{"value": "Mg=="}})
+
+	keys := []string{"first", "second"}
+	results, err := suite.service.DeleteMany(context.Background(), keys, secrets.BulkOptions{Atomic: true})
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrNotFoundStatusText)
+	suite.Require().NotErrorIs(err, secretsmanagererrors.ErrNotAttempted)
+	suite.Require().ErrorIs(results[0].Err, secretsmanagererrors.ErrNotFoundStatusText)
+	suite.Require().ErrorIs(results[1].Err, secretsmanagererrors.ErrNotAttempted)
+}
+
+func (suite *SecretsSuite) TestCreateManyAtomicRollsBackAfterCancel() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gock.New(testDummyEndpoint).
+		Post("/v1/first").
+		Reply(http.StatusOK)
+	gock.New(testDummyEndpoint).
+		Post("/v1/second").
+		AddMatcher(func(*http.Request, *gock.Request) (bool, error) {
+			// The caller gives up, while the second secret is being created.
+			cancel()
+			return true, nil
+		}).
+		Reply(http.StatusOK)
+	gock.New(testDummyEndpoint).
+		Delete("/v1/first").
+		Reply(http.StatusNoContent)
+
+	uscs := []secrets.UserSecret{
+		{Key: "first", Value: []byte("1")},
+		{Key: "second", Value: []byte("2")},
+	}
+	results, err := suite.service.CreateMany(ctx, uscs, secrets.BulkOptions{Workers: 1, Atomic: true})
+	suite.Require().ErrorIs(err, context.Canceled)
+
+	suite.Equal(secrets.ItemResult{Key: "first", RolledBack: true}, results[0])
+	suite.Require().ErrorIs(results[1].Err, context.Canceled)
+}
+
+// newCachedService returns a service with a cache, that is served by the same gock mocks.
+func (suite *SecretsSuite) newCachedService(opts cache.Options) *secrets.Service {
+	auth, err := auth.NewKeystoneTokenAuth("dummy")
+	suite.Require().NoError(err)
+
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	gock.InterceptClient(httpClient)
+
+	return secrets.New(testDummyEndpoint, httpclient.New(auth, httpClient), secrets.WithCache(opts))
+}
+
+func (suite *SecretsSuite) TestGetCachedInvalidatedByUpdate() {
+	service := suite.newCachedService(cache.Options{TTL: time.Hour})
+
+	gock.New(testDummyEndpoint).
+		Get("/v1/" + testDummyKey).
+		Reply(http.StatusOK).
+		JSON(map[string]any{"name": testDummyKey, "version": map[string]any{"value": "djE=", "version_id": 1}})
+	gock.New(testDummyEndpoint).
+		Put("/v1/" + testDummyKey).
+		Reply(http.StatusOK)
+	gock.New(testDummyEndpoint).
+		Get("/v1/" + testDummyKey).
+		Reply(http.StatusOK).
+		JSON(map[string]any{"name": testDummyKey, "version": map[string]any{"value": "djI=", "version_id": 2}})
+
+	ctx := context.Background()
+	for range 2 {
+		sc, err := service.Get(ctx, testDummyKey)
+		suite.Require().NoError(err)
+		suite.Equal([]byte("v1"), sc.Version.Value)
+
+		// Changes of a returned value don't get to the cache.
+		sc.Version.Value[0] = 'x'
+	}
+
+	err := service.Update(ctx, secrets.UserSecret{Key: testDummyKey, Value: []byte("v2")})
+	suite.Require().NoError(err)
+
+	sc, err := service.Get(ctx, testDummyKey)
+	suite.Require().NoError(err)
+	suite.Equal([]byte("v2"), sc.Version.Value)
+	suite.EqualValues(2, sc.Version.VersionID)
+}
+
+func (suite *SecretsSuite) TestGetCachedNotFoundInvalidatedByCreate() {
+	service := suite.newCachedService(cache.Options{TTL: time.Hour, NegativeTTL: time.Hour})
+
+	gock.New(testDummyEndpoint).
+		Get("/v1/" + testDummyKey).
+		Reply(http.StatusNotFound).
+		JSON(map[string]string{"status_text": "NOT_FOUND"})
+	gock.New(testDummyEndpoint).
+		Post("/v1/" + testDummyKey).
+		Reply(http.StatusOK)
+	gock.New(testDummyEndpoint).
+		Get("/v1/" + testDummyKey).
+		Reply(http.StatusOK).
+		JSON(map[string]any{"name": testDummyKey, "version": map[string]any{"value": "djE="}})
+
+	ctx := context.Background()
+	for range 2 {
+		_, err := service.Get(ctx, testDummyKey)
+		suite.Require().ErrorIs(err, secretsmanagererrors.ErrNotFoundStatusText)
+	}
+
+	err := service.Create(ctx, secrets.UserSecret{Key: testDummyKey, Value: []byte("v1")})
+	suite.Require().NoError(err)
+
+	sc, err := service.Get(ctx, testDummyKey)
+	suite.Require().NoError(err)
+	suite.Equal([]byte("v1"), sc.Version.Value)
+}
@@ -0,0 +1,343 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+// SchemaValidator validates a decoded document: objects are map[string]any,
+// arrays are []any and numbers are json.Number, both for JSON and YAML documents.
+// It is satisfied by *jsonschema.Schema of github.com/santhosh-tekuri/jsonschema.
+type SchemaValidator interface {
+	Validate(v any) error
+}
+
+// Validator is implemented by types of structured values, that check themselves
+// after they have been decoded by GetJSON or GetYAML and before they are encoded by PutJSON or PutYAML.
+type Validator interface {
+	Validate() error
+}
+
+// StructuredOption is a functional parameter for GetJSON, PutJSON, GetYAML and PutYAML.
+type StructuredOption func(*structuredOptions)
+
+type structuredOptions struct {
+	allowUnknownFields bool
+	schema             SchemaValidator
+}
+
+// WithUnknownFields allows fields, that are absent in the type of a value, they are ignored.
+func WithUnknownFields() StructuredOption {
+	return func(o *structuredOptions) {
+		o.allowUnknownFields = true
+	}
+}
+
+// WithSchema validates a document against a schema before it is decoded or after it is encoded.
+func WithSchema(schema SchemaValidator) StructuredOption {
+	return func(o *structuredOptions) {
+		o.schema = schema
+	}
+}
+
+// GetJSON gets the secret and decodes its value from JSON into T. Decoding is strict:
+// unknown fields, trailing data and mismatched types are rejected with ErrInvalidSecretValue.
+// Errors never contain the value of the secret.
+func GetJSON[T any](ctx context.Context, s *Service, key string, opts ...StructuredOption) (T, error) {
+	return getStructured[T](ctx, s, key, jsonCodec{}, opts)
+}
+
+// PutJSON encodes value into JSON and puts it into the secret, see Put.
+func PutJSON[T any](ctx context.Context, s *Service, key string, value T, opts ...StructuredOption) (PutResult, error) {
+	return putStructured(ctx, s, key, value, jsonCodec{}, opts)
+}
+
+// GetYAML gets the secret and decodes its value from YAML into T, the same way as GetJSON.
+func GetYAML[T any](ctx context.Context, s *Service, key string, opts ...StructuredOption) (T, error) {
+	return getStructured[T](ctx, s, key, yamlCodec{}, opts)
+}
+
+// PutYAML encodes value into YAML and puts it into the secret, see Put.
+func PutYAML[T any](ctx context.Context, s *Service, key string, value T, opts ...StructuredOption) (PutResult, error) {
+	return putStructured(ctx, s, key, value, yamlCodec{}, opts)
+}
+
+// codec encodes and decodes structured values, errors of it must not contain the value.
+type codec interface {
+	name() string
+	encode(v any) ([]byte, error)
+	decode(data []byte, v any, allowUnknownFields bool) error
+	document(data []byte) (any, error)
+}
+
+func getStructured[T any](ctx context.Context, s *Service, key string, c codec, opts []StructuredOption) (T, error) {
+	var value T
+
+	sc, err := s.Get(ctx, key)
+	if err != nil {
+		return value, err
+	}
+
+	o := newStructuredOptions(opts)
+	err = validateSchema(c, sc.Version.Value, o.schema)
+	if err != nil {
+		return value, invalidValue(key, err)
+	}
+
+	err = c.decode(sc.Version.Value, &value, o.allowUnknownFields)
+	if err != nil {
+		return value, invalidValue(key, fmt.Errorf("can't decode %s into %T: %w", c.name(), value, err))
+	}
+
+	err = validateValue(value)
+	if err != nil {
+		return value, invalidValue(key, err)
+	}
+
+	return value, nil
+}
+
+func putStructured[T any](
+	ctx context.Context, s *Service, key string, value T, c codec, opts []StructuredOption,
+) (PutResult, error) {
+	err := validateValue(value)
+	if err != nil {
+		return 0, invalidValue(key, err)
+	}
+
+	data, err := c.encode(value)
+	if err != nil {
+		return 0, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrCannotMarshalSecretBody,
+			Desc: fmt.Sprintf("can't encode %T into %s for secret %s", value, c.name(), key),
+		}
+	}
+
+	err = validateSchema(c, data, newStructuredOptions(opts).schema)
+	if err != nil {
+		return 0, invalidValue(key, err)
+	}
+
+	return s.Put(ctx, UserSecret{Key: key, Value: data})
+}
+
+func newStructuredOptions(opts []StructuredOption) structuredOptions {
+	var o structuredOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// validateSchema checks a document against a schema. Messages of schema validators may quote
+// the document, so only the fact of the failure is reported.
+func validateSchema(c codec, data []byte, schema SchemaValidator) error {
+	if schema == nil {
+		return nil
+	}
+
+	doc, err := c.document(data)
+	if err != nil {
+		return fmt.Errorf("can't decode %s: %w", c.name(), err)
+	}
+
+	if schema.Validate(doc) != nil {
+		return errors.New("value doesn't match the schema")
+	}
+
+	return nil
+}
+
+// validateValue calls Validate of a value, if it is a Validator. Its message is reported as is,
+// as it is written by the owner of the type.
+func validateValue(value any) error {
+	v, ok := value.(Validator)
+	if !ok {
+		return nil
+	}
+
+	err := v.Validate()
+	if err != nil {
+		return fmt.Errorf("%T is invalid: %w", value, err)
+	}
+
+	return nil
+}
+
+func invalidValue(key string, err error) error {
+	return secretsmanagererrors.Error{
+		Err:  secretsmanagererrors.ErrInvalidSecretValue,
+		Desc: fmt.Sprintf("secret %s: %s", key, err),
+	}
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) name() string {
+	return "JSON"
+}
+
+func (jsonCodec) encode(v any) ([]byte, error) {
+	return json.Marshal(v) //nolint:wrapcheck // The error is replaced by the caller.
+}
+
+func (jsonCodec) decode(data []byte, v any, allowUnknownFields bool) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if !allowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	err := dec.Decode(v)
+	if err != nil {
+		return safeJSONError(err)
+	}
+
+	if _, err = dec.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after the top-level value")
+	}
+
+	return nil
+}
+
+func (jsonCodec) document(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc any
+	err := dec.Decode(&doc)
+	if err != nil {
+		return nil, safeJSONError(err)
+	}
+
+	return doc, nil
+}
+
+// safeJSONError replaces errors of encoding/json, that may quote the document, with ones that don't.
+func safeJSONError(err error) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("invalid syntax at offset %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		// Value describes a JSON value like "number -5", only its kind is kept.
+		kind, _, _ := strings.Cut(typeErr.Value, " ")
+		return fmt.Errorf("field %q: can't use %s as %s", typeErr.Field, kind, typeErr.Type)
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return errors.New("unexpected end of the document")
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// Only a name of a field is quoted.
+		return errors.New(strings.TrimPrefix(err.Error(), "json: "))
+	default:
+		return errors.New("invalid document")
+	}
+}
+
+type yamlCodec struct{}
+
+func (yamlCodec) name() string {
+	return "YAML"
+}
+
+func (yamlCodec) encode(v any) ([]byte, error) {
+	return yaml.Marshal(v) //nolint:wrapcheck // The error is replaced by the caller.
+}
+
+func (yamlCodec) decode(data []byte, v any, allowUnknownFields bool) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(!allowUnknownFields)
+
+	err := dec.Decode(v)
+	if err != nil {
+		return safeYAMLError(err)
+	}
+
+	return yamlEOF(dec)
+}
+
+func (yamlCodec) document(data []byte) (any, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+
+	var doc any
+	err := dec.Decode(&doc)
+	if err != nil {
+		return nil, safeYAMLError(err)
+	}
+
+	err = yamlEOF(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	return yamlNumbers(doc), nil
+}
+
+// yamlEOF checks that a decoder has no documents left, a stream of documents isn't a single value.
+func yamlEOF(dec *yaml.Decoder) error {
+	var next yaml.Node
+	if err := dec.Decode(&next); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected document after the first one")
+	}
+
+	return nil
+}
+
+// yamlNumbers replaces numbers of a YAML document with json.Number, as they are in JSON documents.
+// Infinities and NaN, which JSON doesn't have, are kept as float64.
+func yamlNumbers(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, item := range v {
+			v[k] = yamlNumbers(item)
+		}
+	case map[any]any:
+		for k, item := range v {
+			v[k] = yamlNumbers(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = yamlNumbers(item)
+		}
+	case int:
+		return json.Number(strconv.Itoa(v))
+	case uint64:
+		return json.Number(strconv.FormatUint(v, 10))
+	case float64:
+		if !math.IsInf(v, 0) && !math.IsNaN(v) {
+			return json.Number(strconv.FormatFloat(v, 'g', -1, 64))
+		}
+	}
+
+	return v
+}
+
+// yamlQuoted matches values, that gopkg.in/yaml.v3 quotes in its errors like
+// "line 3: cannot unmarshal !!str `hunter2` into int". Quotes aren't escaped, so a value can contain
+// backticks itself: everything from the first to the last backtick of a line is matched.
+var yamlQuoted = regexp.MustCompile("`.*`") //nolint:gochecknoglobals
+
+// safeYAMLError removes quoted values from errors of gopkg.in/yaml.v3.
+func safeYAMLError(err error) error {
+	if errors.Is(err, io.EOF) {
+		return errors.New("empty document")
+	}
+
+	msg := strings.TrimPrefix(err.Error(), "yaml: ")
+	return errors.New(yamlQuoted.ReplaceAllString(msg, "[REDACTED]"))
+}
@@ -0,0 +1,185 @@
+package secrets_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/h2non/gock"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+	"github.com/selectel/secretsmanager-go/service/secrets"
+)
+
+const testDummyPassword = "hunter2-never-echoed"
+
+type dbConfig struct {
+	User     string `json:"user" yaml:"user"`
+	Password string `json:"password" yaml:"password"`
+	Port     int    `json:"port" yaml:"port"`
+}
+
+func (c dbConfig) Validate() error {
+	if len(c.User) == 0 {
+		return errors.New("user is empty")
+	}
+	return nil
+}
+
+// echoingSchema is a schema validator, which message quotes the document.
+type echoingSchema struct{}
+
+func (echoingSchema) Validate(v any) error {
+	doc, _ := v.(map[string]any)
+	return errors.New("bad password " + doc["password"].(string))
+}
+
+func mockGetValue(value string) {
+	gock.New(testDummyEndpoint).
+		Get(testDummyKey).
+		Reply(http.StatusOK).
+		JSON(map[string]any{
+			"name":    testDummyKey,
+			"version": map[string]any{"value": base64.StdEncoding.EncodeToString([]byte(value))},
+		})
+}
+
+func (suite *SecretsSuite) TestGetJSON() {
+	mockGetValue(`{"user":"app","password":"` + testDummyPassword + `","port":5432}`)
+
+	got, err := secrets.GetJSON[dbConfig](context.Background(), suite.service, testDummyKey)
+	suite.Require().NoError(err)
+	suite.Equal(dbConfig{User: "app", Password: testDummyPassword, Port: 5432}, got)
+}
+
+func (suite *SecretsSuite) TestGetJSONInvalid() {
+	tests := map[string]struct {
+		value   string
+		opts    []secrets.StructuredOption
+		expDesc string
+	}{
+		"Unknown Field": {
+			`{"user":"app","password":"` + testDummyPassword + `","host":"db"}`,
+			nil,
+			`unknown field "host"`,
+		},
+		"Type Mismatch": {
+			`{"user":"app","password":"x","port":"` + testDummyPassword + `"}`,
+			nil,
+			`field "port": can't use string as int`,
+		},
+		"Trailing Data": {
+			`{"user":"app"} "` + testDummyPassword + `"`,
+			nil,
+			"unexpected data after the top-level value",
+		},
+		"Syntax": {
+			`{"user":"app","password":` + testDummyPassword,
+			nil,
+			"invalid syntax at offset",
+		},
+		"Validator": {
+			`{"password":"` + testDummyPassword + `"}`,
+			nil,
+			"user is empty",
+		},
+		"Schema": {
+			`{"user":"app","password":"` + testDummyPassword + `"}`,
+			[]secrets.StructuredOption{secrets.WithSchema(echoingSchema{})},
+			"value doesn't match the schema",
+		},
+	}
+
+	for name, test := range tests {
+		suite.Run(name, func() {
+			mockGetValue(test.value)
+
+			_, err := secrets.GetJSON[dbConfig](context.Background(), suite.service, testDummyKey, test.opts...)
+			suite.Require().ErrorIs(err, secretsmanagererrors.ErrInvalidSecretValue)
+			suite.Require().ErrorContains(err, test.expDesc)
+			suite.NotContains(err.Error(), testDummyPassword)
+		})
+	}
+}
+
+func (suite *SecretsSuite) TestGetJSONUnknownFieldsAllowed() {
+	mockGetValue(`{"user":"app","host":"db"}`)
+
+	ctx := context.Background()
+	got, err := secrets.GetJSON[dbConfig](ctx, suite.service, testDummyKey, secrets.WithUnknownFields())
+	suite.Require().NoError(err)
+	suite.Equal("app", got.User)
+}
+
+func (suite *SecretsSuite) TestPutJSON() {
+	gock.New(testDummyEndpoint).
+		Post(testDummyKey).
+		MatchType("json").
+		JSON(map[string]string{
+			"value": base64.StdEncoding.EncodeToString([]byte(`{"user":"app","password":"pass","port":5432}`)),
+		}).
+		Reply(http.StatusOK)
+
+	ctx := context.Background()
+	res, err := secrets.PutJSON(ctx, suite.service, testDummyKey, dbConfig{User: "app", Password: "pass", Port: 5432})
+	suite.Require().NoError(err)
+	suite.Equal(secrets.PutCreatedSecret, res)
+
+	_, err = secrets.PutJSON(ctx, suite.service, testDummyKey, dbConfig{Password: testDummyPassword})
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrInvalidSecretValue)
+	suite.NotContains(err.Error(), testDummyPassword)
+}
+
+func (suite *SecretsSuite) TestGetYAML() {
+	mockGetValue("user: app\npassword: " + testDummyPassword + "\nport: 5432\n")
+
+	got, err := secrets.GetYAML[dbConfig](context.Background(), suite.service, testDummyKey)
+	suite.Require().NoError(err)
+	suite.Equal(dbConfig{User: "app", Password: testDummyPassword, Port: 5432}, got)
+
+	mockGetValue("user: app\nport: " + testDummyPassword + "\n")
+
+	_, err = secrets.GetYAML[dbConfig](context.Background(), suite.service, testDummyKey)
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrInvalidSecretValue)
+	suite.Require().ErrorContains(err, "line 2: cannot unmarshal !!str [REDACTED] into int")
+	suite.NotContains(err.Error(), testDummyPassword)
+
+	mockGetValue("user: app\nport: a`XYZ`b\n")
+
+	_, err = secrets.GetYAML[dbConfig](context.Background(), suite.service, testDummyKey)
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrInvalidSecretValue)
+	suite.Require().ErrorContains(err, "line 2: cannot unmarshal !!str [REDACTED] into int")
+	suite.NotContains(err.Error(), "XYZ")
+}
+
+func (suite *SecretsSuite) TestGetYAMLSecondDocument() {
+	mockGetValue("user: app\n---\npassword: " + testDummyPassword + "\n")
+
+	_, err := secrets.GetYAML[dbConfig](context.Background(), suite.service, testDummyKey)
+	suite.Require().ErrorIs(err, secretsmanagererrors.ErrInvalidSecretValue)
+	suite.Require().ErrorContains(err, "unexpected document after the first one")
+	suite.NotContains(err.Error(), testDummyPassword)
+}
+
+// recordingSchema is a schema validator, which keeps the document.
+type recordingSchema struct {
+	doc *any
+}
+
+func (s recordingSchema) Validate(v any) error {
+	*s.doc = v
+	return nil
+}
+
+func (suite *SecretsSuite) TestGetYAMLSchemaNumbers() {
+	mockGetValue("user: app\nport: 5432\nratio: 0.5\n")
+
+	var doc any
+	ctx := context.Background()
+	opts := []secrets.StructuredOption{secrets.WithSchema(recordingSchema{&doc}), secrets.WithUnknownFields()}
+	_, err := secrets.GetYAML[dbConfig](ctx, suite.service, testDummyKey, opts...)
+	suite.Require().NoError(err)
+	suite.Equal(map[string]any{"user": "app", "port": json.Number("5432"), "ratio": json.Number("0.5")}, doc)
+}
@@ -0,0 +1,63 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/selectel/secretsmanager-go/secretsmanagererrors"
+)
+
+// ValueFromBase64 decodes a value, that is already encoded to base64, for UserSecret.Value.
+// It is a compatibility path for code, that used to encode values by itself:
+// the SDK encodes UserSecret.Value on every write, so an encoded value passed as is would be stored encoded twice.
+func ValueFromBase64(encoded string) ([]byte, error) {
+	value, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, secretsmanagererrors.Error{
+			Err:  secretsmanagererrors.ErrInvalidSecretValue,
+			Desc: "value is not a valid base64: " + err.Error(),
+		}
+	}
+
+	return value, nil
+}
+
+// Base64Value returns the value of the secret as it is stored by Secrets Manager: StoredValue,
+// if the version has been received from Secrets Manager, or Value encoded to base64 otherwise.
+// It is the value, that Get used to return, so a raw value (see RawValue) is returned as is.
+func (v SecretVersion) Base64Value() string {
+	if len(v.StoredValue) > 0 {
+		return v.StoredValue
+	}
+
+	return base64.StdEncoding.EncodeToString(v.Value)
+}
+
+// UnmarshalJSON decodes the value from base64 and keeps the stored one in StoredValue. A value, that isn't
+// valid base64, is kept as is and RawValue is set, so a single secret written without encoding doesn't fail
+// the whole response.
+func (v *SecretVersion) UnmarshalJSON(data []byte) error {
+	type version SecretVersion
+	aux := struct {
+		*version
+		Value *string `json:"value"`
+	}{version: (*version)(v)}
+
+	err := json.Unmarshal(data, &aux)
+	if err != nil {
+		return err //nolint:wrapcheck // Callers wrap errors of unmarshalling.
+	}
+
+	v.Value, v.RawValue, v.StoredValue = nil, false, ""
+	if aux.Value == nil {
+		return nil
+	}
+	v.StoredValue = *aux.Value
+
+	v.Value, err = base64.StdEncoding.DecodeString(*aux.Value)
+	if err != nil {
+		v.Value, v.RawValue = []byte(*aux.Value), true
+	}
+
+	return nil
+}
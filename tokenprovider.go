@@ -0,0 +1,31 @@
+package secretsmanager
+
+import (
+	"context"
+
+	"github.com/selectel/secretsmanager-go/internal/auth"
+)
+
+// TokenProvider — a source of Keystone tokens used to authenticate requests
+// to Secrets Manager API, it is called before every request and has to be safe for concurrent use.
+// Implement it to use your own credentials source (a broker, a file rotated by a sidecar, etc.)
+// and pass it to the client using WithTokenProvider.
+type TokenProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// RenewableTokenProvider — a TokenProvider that is able to provide a new token,
+// after the current one has been rejected by the backend.
+// If a TokenProvider implements it, requests failed with 401 are replayed once with a new token.
+type RenewableTokenProvider interface {
+	TokenProvider
+
+	// InvalidateToken is called with the rejected token,
+	// implementations should drop it only if it has not been replaced yet.
+	InvalidateToken(rejected string)
+}
+
+// NewKeystoneTokenProvider returns a TokenProvider, that always provides the given static Keystone token.
+func NewKeystoneTokenProvider(token string) (TokenProvider, error) {
+	return auth.NewKeystoneTokenAuth(token) //nolint:wrapcheck // NewKeystoneTokenAuth already wraps the error.
+}